@@ -0,0 +1,237 @@
+package maclookup
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+//ErrCircuitOpen is wrapped by CircuitOpenError.
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+//BreakerState is the state of a Breaker.
+type BreakerState int
+
+//Breaker states.
+const (
+	BreakerClosed BreakerState = iota
+	BreakerOpen
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+
+	return fmt.Sprintf("BreakerState(%d)", int(s))
+}
+
+//CircuitOpenError is returned without sending the request while the breaker is open.
+type CircuitOpenError struct {
+	RetryAt time.Time
+}
+
+func (c *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit breaker open until %s", c.RetryAt.Format(time.RFC3339))
+}
+
+func (c *CircuitOpenError) Unwrap() error {
+	return ErrCircuitOpen
+}
+
+//Breaker is a circuit breaker shared by the requests of one or more clients.
+//It opens after a number of consecutive transport errors or 5xx responses, fails every
+//request fast while open, then lets probe requests through (half-open): the breaker closes
+//when the probes succeed and opens again when one of them fails.
+type Breaker struct {
+	mu            sync.Mutex
+	threshold     int
+	openFor       time.Duration
+	probes        int
+	state         BreakerState
+	failures      int
+	openedAt      time.Time
+	inflight      int
+	successes     int
+	generation    uint64
+	onStateChange func(from, to BreakerState)
+	now           func() time.Time
+}
+
+//NewBreaker creates a breaker opening after threshold consecutive failures for openFor,
+//with a single half-open probe.
+func NewBreaker(threshold int, openFor time.Duration) *Breaker {
+	if threshold < 1 {
+		threshold = 1
+	}
+
+	return &Breaker{threshold: threshold, openFor: openFor, probes: 1, now: time.Now}
+}
+
+//WithHalfOpenProbes sets the number of concurrent half-open probes, all of which must succeed to close the breaker.
+func (b *Breaker) WithHalfOpenProbes(n int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if n < 1 {
+		n = 1
+	}
+
+	b.probes = n
+}
+
+//OnStateChange sets the function called on every state change.
+func (b *Breaker) OnStateChange(f func(from, to BreakerState)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.onStateChange = f
+}
+
+//State returns the current state.
+func (b *Breaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerOpen && !b.now().Before(b.openedAt.Add(b.openFor)) {
+		return BreakerHalfOpen
+	}
+
+	return b.state
+}
+
+//WithBreaker makes every request go through b.
+func (c *Client) WithBreaker(b *Breaker) {
+	c.breaker = b
+}
+
+// allow reports whether a request can be sent, counting it as a probe when half-open.
+// It returns the generation of the state admitting the request, to be given to record.
+func (b *Breaker) allow() (uint64, error) {
+	if b == nil {
+		return 0, nil
+	}
+
+	b.mu.Lock()
+
+	var changes [][2]BreakerState
+
+	if b.state == BreakerOpen {
+		retryAt := b.openedAt.Add(b.openFor)
+		if b.now().Before(retryAt) {
+			b.mu.Unlock()
+			return 0, &CircuitOpenError{RetryAt: retryAt}
+		}
+
+		changes = b.setState(changes, BreakerHalfOpen)
+	}
+
+	if b.state == BreakerHalfOpen {
+		if b.inflight >= b.probes {
+			b.mu.Unlock()
+			return 0, &CircuitOpenError{RetryAt: b.now()}
+		}
+
+		b.inflight++
+	}
+
+	generation := b.generation
+
+	b.notify(changes)
+
+	return generation, nil
+}
+
+// record updates the breaker with the result of a request allowed by allow in generation.
+// The results of the requests admitted before the last state change are ignored.
+func (b *Breaker) record(generation uint64, err error) {
+	if b == nil {
+		return
+	}
+
+	failed := isServerFailure(err)
+
+	b.mu.Lock()
+
+	if generation != b.generation {
+		b.mu.Unlock()
+		return
+	}
+
+	var changes [][2]BreakerState
+
+	switch b.state {
+	case BreakerClosed:
+		if !failed {
+			b.failures = 0
+			break
+		}
+
+		b.failures++
+		if b.failures >= b.threshold {
+			changes = b.setState(changes, BreakerOpen)
+		}
+	case BreakerHalfOpen:
+		if b.inflight > 0 {
+			b.inflight--
+		}
+
+		if failed {
+			changes = b.setState(changes, BreakerOpen)
+			break
+		}
+
+		b.successes++
+		if b.successes >= b.probes {
+			changes = b.setState(changes, BreakerClosed)
+		}
+	}
+
+	b.notify(changes)
+}
+
+// setState must be called with b.mu held. It returns changes with the transition appended.
+func (b *Breaker) setState(changes [][2]BreakerState, to BreakerState) [][2]BreakerState {
+	from := b.state
+	b.state = to
+	b.failures, b.successes, b.inflight = 0, 0, 0
+	b.generation++
+
+	if to == BreakerOpen {
+		b.openedAt = b.now()
+	}
+
+	return append(changes, [2]BreakerState{from, to})
+}
+
+// notify releases b.mu, then reports changes.
+func (b *Breaker) notify(changes [][2]BreakerState) {
+	f := b.onStateChange
+	b.mu.Unlock()
+
+	if f == nil {
+		return
+	}
+
+	for _, c := range changes {
+		f(c[0], c[1])
+	}
+}
+
+// isServerFailure reports whether err is a transport error or a 5xx response.
+func isServerFailure(err error) bool {
+	var transportErr *HTTPClientError
+	if !errors.As(err, &transportErr) {
+		return false
+	}
+
+	return transportErr.StatusCode == 0 || transportErr.StatusCode >= http.StatusInternalServerError
+}
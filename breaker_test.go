@@ -0,0 +1,164 @@
+package maclookup
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_WithBreaker(t *testing.T) {
+	status := http.StatusInternalServerError
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(status)
+		fmt.Fprintln(w, `{"success":true,"found":false}`)
+	}))
+	defer ts.Close()
+
+	now := time.Now()
+
+	var changes []string
+
+	b := NewBreaker(2, time.Minute)
+	b.now = func() time.Time { return now }
+	b.OnStateChange(func(from, to BreakerState) {
+		changes = append(changes, from.String()+"->"+to.String())
+	})
+
+	client := New()
+	client.WithPrefixURI(ts.URL)
+	client.WithBreaker(b)
+
+	_, err := client.Lookup("000000")
+	assert.False(t, errors.Is(err, ErrCircuitOpen))
+	_, err = client.CompanyName("000000")
+	assert.False(t, errors.Is(err, ErrCircuitOpen))
+	assert.Equal(t, BreakerOpen, b.State())
+
+	_, err = client.Lookup("000000")
+
+	var e *CircuitOpenError
+
+	assert.True(t, errors.As(err, &e))
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+	assert.Equal(t, now.Add(time.Minute), e.RetryAt)
+	assert.True(t, IsRetryable(err))
+	assert.Equal(t, 2, requests)
+
+	// The half-open probe fails: the breaker opens again.
+	now = now.Add(time.Minute)
+	assert.Equal(t, BreakerHalfOpen, b.State())
+	_, _ = client.Lookup("000000")
+	assert.Equal(t, BreakerOpen, b.State())
+	assert.Equal(t, 3, requests)
+
+	// The half-open probe succeeds: the breaker closes.
+	now = now.Add(time.Minute)
+	status = http.StatusOK
+	_, err = client.Lookup("000000")
+	assert.Nil(t, err)
+	assert.Equal(t, BreakerClosed, b.State())
+
+	assert.Equal(t, []string{"closed->open", "open->half-open", "half-open->open", "open->half-open", "half-open->closed"}, changes)
+}
+
+func TestClient_WithBreaker_reportsRejections(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	var done []RequestEvent
+
+	metrics := NewMetrics()
+
+	client := New()
+	client.WithPrefixURI(ts.URL)
+	client.WithBreaker(NewBreaker(1, time.Minute))
+	client.WithMetrics(metrics)
+	client.WithObserver(ObserverFuncs{OnDone: func(e RequestEvent) { done = append(done, e) }})
+
+	_, _ = client.Lookup("000000")
+	_, err := client.Lookup("000000")
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+
+	assert.Len(t, done, 2)
+	assert.Equal(t, OutcomeCircuitOpen, done[1].Outcome)
+	assert.Equal(t, err, done[1].Err)
+	assert.Zero(t, done[1].StatusCode)
+	assert.Equal(t, OutcomeCircuitOpen, Outcome(false, false, err))
+	assert.Equal(t, uint64(1), metrics.Requests(EndpointLookup, OutcomeCircuitOpen))
+}
+
+func TestBreaker_ignoresAPIErrors(t *testing.T) {
+	b := NewBreaker(1, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		generation, err := b.allow()
+		assert.Nil(t, err)
+		b.record(generation, &RateLimitsExceeded{})
+		generation, err = b.allow()
+		assert.Nil(t, err)
+		b.record(generation, &HTTPClientError{Err: ErrNotFound, ErrorResponse: ErrorResponse{StatusCode: http.StatusNotFound}})
+	}
+
+	assert.Equal(t, BreakerClosed, b.State())
+}
+
+func TestBreaker_halfOpenProbes(t *testing.T) {
+	now := time.Now()
+	b := NewBreaker(1, time.Second)
+	b.now = func() time.Time { return now }
+	b.WithHalfOpenProbes(2)
+
+	generation, err := b.allow()
+	assert.Nil(t, err)
+	b.record(generation, &HTTPClientError{Err: errors.New("connection refused")})
+	assert.Equal(t, BreakerOpen, b.State())
+
+	now = now.Add(time.Second)
+	probe1, err := b.allow()
+	assert.Nil(t, err)
+	probe2, err := b.allow()
+	assert.Nil(t, err)
+	_, err = b.allow()
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+
+	b.record(probe1, nil)
+	assert.Equal(t, BreakerHalfOpen, b.State())
+	b.record(probe2, nil)
+	assert.Equal(t, BreakerClosed, b.State())
+}
+
+func TestBreaker_ignoresStaleResults(t *testing.T) {
+	now := time.Now()
+	b := NewBreaker(1, time.Second)
+	b.now = func() time.Time { return now }
+
+	failing, err := b.allow()
+	assert.Nil(t, err)
+	slow, err := b.allow()
+	assert.Nil(t, err)
+
+	b.record(failing, &HTTPClientError{Err: errors.New("connection refused")})
+	assert.Equal(t, BreakerOpen, b.State())
+
+	now = now.Add(time.Second)
+	probe, err := b.allow()
+	assert.Nil(t, err)
+
+	// The request admitted while closed is neither a probe nor closes the breaker.
+	b.record(slow, nil)
+	assert.Equal(t, BreakerHalfOpen, b.State())
+	_, err = b.allow()
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+
+	b.record(probe, nil)
+	assert.Equal(t, BreakerClosed, b.State())
+}
@@ -0,0 +1,295 @@
+package maclookup
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/logocomune/maclookup-go/internal/macaddr"
+)
+
+//ClassRulesVersion is the version of the classification rules file format.
+const ClassRulesVersion = 1
+
+//Category is a device class.
+type Category string
+
+//Device categories.
+const (
+	CategoryUnknown  Category = ""
+	CategoryPhone    Category = "phone"
+	CategoryLaptop   Category = "laptop"
+	CategoryNetwork  Category = "network"
+	CategoryIoT      Category = "iot"
+	CategoryPrinter  Category = "printer"
+	CategoryCamera   Category = "camera"
+	CategoryVirtual  Category = "virtual"
+	CategoryTV       Category = "tv"
+	CategoryConsole  Category = "console"
+	CategoryComputer Category = "computer"
+)
+
+//Default confidences of the rules without Confidence, by kind of match.
+const (
+	DefaultPrefixConfidence  = 0.9
+	DefaultRangeConfidence   = 0.8
+	DefaultCompanyConfidence = 0.6
+)
+
+//Classification is the device category of a MAC address with a confidence between 0 and 1.
+type Classification struct {
+	Category   Category `json:"category"`
+	Confidence float64  `json:"confidence"`
+}
+
+//ClassRule assigns Category to the MAC addresses starting with one of Prefixes (2 to 12 hex digits),
+//within one of Ranges ("first-last" addresses) or assigned to one of Companies (compared by normalized key).
+type ClassRule struct {
+	Category   Category `json:"category"`
+	Confidence float64  `json:"confidence,omitempty"`
+	Companies  []string `json:"companies,omitempty"`
+	Prefixes   []string `json:"prefixes,omitempty"`
+	Ranges     []string `json:"ranges,omitempty"`
+}
+
+// classRulesFile is the rules file: {"version":1,"rules":[...]}.
+type classRulesFile struct {
+	Version int         `json:"version"`
+	Rules   []ClassRule `json:"rules"`
+}
+
+type classRange struct {
+	first, last string
+	class       Classification
+}
+
+//Classifier assigns a device category to MAC addresses from rules.
+//A prefix match wins over a range match, which wins over a company match;
+//the longest prefix and the narrowest range win, and later rules replace earlier ones for the same prefix or company.
+//NewClassifier builds all of its tables, which Classify only reads: one Classifier can serve concurrent lookups.
+type Classifier struct {
+	prefixes  map[string]Classification
+	lengths   []int
+	ranges    []classRange
+	companies map[string]Classification
+}
+
+//NewClassifier creates a Classifier from rules.
+func NewClassifier(rules ...ClassRule) (*Classifier, error) {
+	c := &Classifier{prefixes: make(map[string]Classification), companies: make(map[string]Classification)}
+	lengths := make(map[int]bool)
+	ranges := make(map[string]int)
+
+	for _, r := range rules {
+		if r.Category == CategoryUnknown {
+			return nil, fmt.Errorf("classification rule without category: %+v", r)
+		}
+
+		if r.Confidence < 0 || r.Confidence > 1 {
+			return nil, fmt.Errorf("category %s: confidence %v out of [0, 1]", r.Category, r.Confidence)
+		}
+
+		for _, p := range r.Prefixes {
+			prefix := macaddr.Clean(p)
+			if !isHex(prefix) || len(prefix) < 2 || len(prefix) > 12 {
+				return nil, fmt.Errorf("category %s: invalid prefix %q", r.Category, p)
+			}
+
+			c.prefixes[prefix] = r.classification(DefaultPrefixConfidence)
+			lengths[len(prefix)] = true
+		}
+
+		for _, rng := range r.Ranges {
+			bounds := strings.Split(rng, "-")
+			if len(bounds) != 2 {
+				bounds = strings.Split(rng, "..")
+			}
+
+			if len(bounds) != 2 {
+				return nil, fmt.Errorf("category %s: invalid range %q", r.Category, rng)
+			}
+
+			first, last := macaddr.Clean(bounds[0]), macaddr.Clean(bounds[1])
+			if len(first) != 12 || len(last) != 12 || !isHex(first) || !isHex(last) || first > last {
+				return nil, fmt.Errorf("category %s: invalid range %q", r.Category, rng)
+			}
+
+			if i, ok := ranges[first+last]; ok {
+				c.ranges[i].class = r.classification(DefaultRangeConfidence)
+				continue
+			}
+
+			ranges[first+last] = len(c.ranges)
+			c.ranges = append(c.ranges, classRange{first: first, last: last, class: r.classification(DefaultRangeConfidence)})
+		}
+
+		for _, company := range r.Companies {
+			key := NormalizeCompany(company).Key
+			if key == "" {
+				return nil, fmt.Errorf("category %s: empty company", r.Category)
+			}
+
+			c.companies[key] = r.classification(DefaultCompanyConfidence)
+		}
+	}
+
+	for n := range lengths {
+		c.lengths = append(c.lengths, n)
+	}
+
+	sort.Sort(sort.Reverse(sort.IntSlice(c.lengths)))
+
+	// Narrowest ranges first.
+	sort.SliceStable(c.ranges, func(i, j int) bool {
+		return rangeWidth(c.ranges[i]) < rangeWidth(c.ranges[j])
+	})
+
+	return c, nil
+}
+
+//LoadClassRules reads classification rules in JSON:
+//{"version":1,"rules":[{"category":"camera","confidence":0.9,"companies":[...],"prefixes":[...],"ranges":[...]}]}.
+func LoadClassRules(r io.Reader) ([]ClassRule, error) {
+	var f classRulesFile
+
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(&f); err != nil {
+		return nil, err
+	}
+
+	if f.Version < 0 || f.Version > ClassRulesVersion {
+		return nil, fmt.Errorf("unsupported classification rules version %d", f.Version)
+	}
+
+	return f.Rules, nil
+}
+
+//OpenClassifier creates a Classifier from the built-in rules followed by the rules of a file
+//in the LoadClassRules format, which take precedence.
+func OpenClassifier(path string) (*Classifier, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	rules, err := LoadClassRules(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return NewClassifier(append(DefaultClassRules(), rules...)...)
+}
+
+var (
+	defaultClassifier     *Classifier
+	defaultClassifierOnce sync.Once
+)
+
+//DefaultClassifier returns the Classifier of the built-in rules.
+func DefaultClassifier() *Classifier {
+	defaultClassifierOnce.Do(func() {
+		c, err := NewClassifier(DefaultClassRules()...)
+		if err != nil {
+			panic(err)
+		}
+
+		defaultClassifier = c
+	})
+
+	return defaultClassifier
+}
+
+//WithClassifier sets the Classification of every result.
+func (c *Client) WithClassifier(cl *Classifier) {
+	c.classifier = cl
+}
+
+//Classify returns the category of mac, a MAC address or prefix, assigned to company.
+//It returns the zero Classification when no rule matches.
+func (c *Classifier) Classify(company, mac string) Classification {
+	m := macaddr.Clean(mac)
+
+	for _, n := range c.lengths {
+		if len(m) < n {
+			continue
+		}
+
+		if class, ok := c.prefixes[m[:n]]; ok {
+			return class
+		}
+	}
+
+	if len(m) >= 6 && len(m) <= 12 && isHex(m) {
+		first := m + strings.Repeat("0", 12-len(m))
+		last := m + strings.Repeat("F", 12-len(m))
+
+		for _, r := range c.ranges {
+			if first >= r.first && last <= r.last {
+				return r.class
+			}
+		}
+	}
+
+	return c.companies[NormalizeCompany(company).Key]
+}
+
+func (r ClassRule) classification(confidence float64) Classification {
+	if r.Confidence != 0 {
+		confidence = r.Confidence
+	}
+
+	return Classification{Category: r.Category, Confidence: confidence}
+}
+
+// rangeWidth returns the number of addresses of r minus one.
+func rangeWidth(r classRange) uint64 {
+	first, _ := strconv.ParseUint(r.first, 16, 64)
+	last, _ := strconv.ParseUint(r.last, 16, 64)
+
+	return last - first
+}
+
+//DefaultClassRules returns the built-in classification rules.
+func DefaultClassRules() []ClassRule {
+	return []ClassRule{
+		{Category: CategoryNetwork, Confidence: 0.8, Companies: []string{
+			"Cisco Systems, Inc", "Cisco Meraki", "Juniper Networks", "Arista Networks", "Ubiquiti Inc", "Ubiquiti Networks Inc.",
+			"Aruba, a Hewlett Packard Enterprise Company", "Aruba Networks", "Ruckus Wireless", "Extreme Networks, Inc.",
+			"Fortinet, Inc.", "Palo Alto Networks", "Routerboard.com", "MikroTik", "TP-LINK TECHNOLOGIES CO.,LTD.",
+			"NETGEAR", "Zyxel Communications Corporation", "D-Link International", "AVM GmbH", "Cambium Networks Limited",
+		}},
+		{Category: CategoryIoT, Confidence: 0.9, Companies: []string{
+			"Espressif Inc.", "Tuya Smart Inc.", "Shenzhen Ai-Thinker Technology Co., Ltd", "Allterco Robotics ltd",
+			"Raspberry Pi Trading Ltd", "Raspberry Pi (Trading) Ltd", "Particle Industries, Inc.", "Nordic Semiconductor ASA",
+			"Silicon Laboratories", "Sonos, Inc.", "Signify B.V.", "Philips Lighting BV", "Nest Labs Inc.", "ecobee inc",
+		}},
+		{Category: CategoryPrinter, Confidence: 0.8, Companies: []string{
+			"Brother Industries, LTD.", "Seiko Epson Corporation", "Lexmark International, Inc.", "KYOCERA Document Solutions Inc.",
+			"Xerox Corporation", "Ricoh Company, Ltd.", "Zebra Technologies Inc", "Konica Minolta Holdings, Inc.",
+		}},
+		{Category: CategoryCamera, Confidence: 0.9, Companies: []string{
+			"Hangzhou Hikvision Digital Technology Co.,Ltd.", "Zhejiang Dahua Technology Co., Ltd.", "Axis Communications AB",
+			"Hanwha Techwin", "Samsung Techwin", "MOBOTIX AG", "Shenzhen Reolink Technology Co.,Ltd", "Vivotek Inc",
+		}},
+		{Category: CategoryPhone, Confidence: 0.8, Companies: []string{
+			"Xiaomi Communications Co Ltd", "OnePlus Technology (Shenzhen) Co., Ltd", "HMD Global Oy", "Motorola Mobility LLC, a Lenovo Company",
+			"GUANGDONG OPPO MOBILE TELECOMMUNICATIONS CORP.,LTD", "vivo Mobile Communication Co., Ltd.", "Fairphone B.V.",
+		}},
+		{Category: CategoryPhone, Confidence: 0.5, Companies: []string{"Samsung Electronics Co.,Ltd"}},
+		{Category: CategoryLaptop, Confidence: 0.6, Companies: []string{
+			"Intel Corporate", "LCFC(HeFei) Electronics Technology co., ltd", "Liteon Technology Corporation",
+			"AzureWave Technology Inc.", "Compal Information (Kunshan) Co., Ltd.", "Quanta Computer Inc.",
+		}},
+		{Category: CategoryTV, Confidence: 0.8, Companies: []string{"Roku, Inc", "TCL King Electrical Appliances (Huizhou) Co., Ltd", "Vizio, Inc"}},
+		{Category: CategoryConsole, Confidence: 0.8, Companies: []string{"Nintendo Co.,Ltd", "Sony Interactive Entertainment Inc.", "Valve Corporation"}},
+		{Category: CategoryVirtual, Confidence: 0.95, Prefixes: virtualPrefixes()},
+	}
+}
@@ -0,0 +1,126 @@
+package maclookup
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifier_Classify(t *testing.T) {
+	c, err := NewClassifier(
+		ClassRule{Category: CategoryIoT, Companies: []string{"Espressif Inc."}},
+		ClassRule{Category: CategoryCamera, Confidence: 0.7, Prefixes: []string{"24:0A:C4:AA"}},
+		ClassRule{Category: CategoryPrinter, Ranges: []string{"70:B3:D5:F2:F0:00-70:B3:D5:F2:FF:FF"}},
+		ClassRule{Category: CategoryNetwork, Ranges: []string{"70B3D5000000..70B3D5FFFFFF"}},
+		ClassRule{Category: CategoryPhone, Companies: []string{"ESPRESSIF SYSTEMS"}},
+	)
+	assert.Nil(t, err)
+
+	assert.Equal(t, Classification{Category: CategoryIoT, Confidence: DefaultCompanyConfidence}, c.Classify("ESPRESSIF, INC", "24:0A:C4:11:22:33"))
+	assert.Equal(t, Classification{Category: CategoryCamera, Confidence: 0.7}, c.Classify("Espressif Inc.", "24:0A:C4:AA:22:33"))
+	assert.Equal(t, Classification{Category: CategoryPrinter, Confidence: DefaultRangeConfidence}, c.Classify("", "70B3D5F2F123"))
+	assert.Equal(t, Classification{Category: CategoryPrinter, Confidence: DefaultRangeConfidence}, c.Classify("", "70B3D5F2F"))
+	assert.Equal(t, Classification{Category: CategoryNetwork, Confidence: DefaultRangeConfidence}, c.Classify("", "70:B3:D5:00:00:01"))
+	assert.Equal(t, Classification{Category: CategoryNetwork, Confidence: DefaultRangeConfidence}, c.Classify("", "70B3D5F"))
+	assert.Equal(t, Classification{Category: CategoryPhone, Confidence: DefaultCompanyConfidence}, c.Classify("Espressif Systems", ""))
+	assert.Equal(t, Classification{}, c.Classify("XEROX CORPORATION", "000000"))
+}
+
+func TestClassifier_laterRulesWin(t *testing.T) {
+	c, err := NewClassifier(append(DefaultClassRules(),
+		ClassRule{Category: CategoryComputer, Companies: []string{"Intel Corporate"}},
+		ClassRule{Category: CategoryNetwork, Confidence: 1, Prefixes: []string{"52:54:00"}},
+	)...)
+	assert.Nil(t, err)
+
+	assert.Equal(t, Classification{Category: CategoryComputer, Confidence: DefaultCompanyConfidence}, c.Classify("Intel Corporate", "001B21"))
+	assert.Equal(t, Classification{Category: CategoryNetwork, Confidence: 1}, c.Classify("", "52:54:00:12:34:56"))
+}
+
+func TestDefaultClassifier(t *testing.T) {
+	c := DefaultClassifier()
+
+	assert.Equal(t, CategoryIoT, c.Classify("Espressif Inc.", "24:0A:C4:11:22:33").Category)
+	assert.Equal(t, CategoryCamera, c.Classify("Hangzhou Hikvision Digital Technology Co.,Ltd.", "").Category)
+	assert.Equal(t, CategoryPrinter, c.Classify("XEROX CORPORATION", "000000").Category)
+	assert.Equal(t, CategoryVirtual, c.Classify("", "52:54:00:12:34:56").Category)
+	assert.Equal(t, CategoryVirtual, c.Classify("", "02:42:ac:11:00:02").Category)
+	assert.Equal(t, CategoryVirtual, c.Classify("VMware, Inc.", "00:50:56:01:02:03").Category)
+	assert.Equal(t, CategoryUnknown, c.Classify("Acme", "AABBCC").Category)
+}
+
+func TestNewClassifier_errors(t *testing.T) {
+	for _, r := range []ClassRule{
+		{Companies: []string{"Acme"}},
+		{Category: CategoryIoT, Confidence: 1.5},
+		{Category: CategoryIoT, Prefixes: []string{"0"}},
+		{Category: CategoryIoT, Prefixes: []string{"00000000000000"}},
+		{Category: CategoryIoT, Prefixes: []string{"ZZ"}},
+		{Category: CategoryIoT, Ranges: []string{"000000000000"}},
+		{Category: CategoryIoT, Ranges: []string{"000000FFFFFF-000000000000"}},
+		{Category: CategoryIoT, Ranges: []string{"000000-000000FFFFFF"}},
+		{Category: CategoryIoT, Companies: []string{" , "}},
+	} {
+		_, err := NewClassifier(r)
+		assert.NotNil(t, err, "%+v", r)
+	}
+}
+
+func TestOpenClassifier(t *testing.T) {
+	dir, err := ioutil.TempDir("", "classify")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "rules.json")
+	assert.Nil(t, ioutil.WriteFile(path, []byte(`{"version":1,"rules":[{"category":"camera","confidence":0.99,"companies":["Acme Cameras Ltd"]}]}`), 0o600))
+
+	c, err := OpenClassifier(path)
+	assert.Nil(t, err)
+	assert.Equal(t, Classification{Category: CategoryCamera, Confidence: 0.99}, c.Classify("ACME CAMERAS", ""))
+	assert.Equal(t, CategoryIoT, c.Classify("Espressif Inc.", "").Category)
+
+	_, err = LoadClassRules(strings.NewReader(`{"version":2,"rules":[]}`))
+	assert.NotNil(t, err)
+
+	_, err = LoadClassRules(strings.NewReader(`{"version":1,"rule":[]}`))
+	assert.NotNil(t, err)
+
+	_, err = OpenClassifier(filepath.Join(dir, "missing.json"))
+	assert.NotNil(t, err)
+}
+
+func TestClient_WithClassifier(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, companyNameSuffix) {
+			fmt.Fprint(w, "Espressif Inc.")
+			return
+		}
+
+		fmt.Fprintln(w, `{"success":true,"found":true,"macPrefix":"240AC4","company":"Espressif Inc."}`)
+	}))
+	defer ts.Close()
+
+	client := New()
+	client.WithPrefixURI(ts.URL)
+
+	resp, err := client.Lookup("24:0A:C4:11:22:33")
+	assert.Nil(t, err)
+	assert.Equal(t, Classification{}, resp.Classification)
+
+	client.WithClassifier(DefaultClassifier())
+
+	resp, err = client.Lookup("24:0A:C4:11:22:33")
+	assert.Nil(t, err)
+	assert.Equal(t, Classification{Category: CategoryIoT, Confidence: 0.9}, resp.Classification)
+
+	name, err := client.CompanyName("24:0A:C4:11:22:33")
+	assert.Nil(t, err)
+	assert.Equal(t, CategoryIoT, name.Classification.Category)
+}
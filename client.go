@@ -1,11 +1,16 @@
 package maclookup
 
 import (
+	"context"
+	"fmt"
 	"net"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/logocomune/maclookup-go/internal/macaddr"
 )
 
 const (
@@ -15,13 +20,28 @@ const (
 	xRateLimit     = "X-RateLimit-Limit"
 	xRateRemaining = "X-RateLimit-Remaining"
 	xRateReset     = "X-RateLimit-Reset"
+
+	redacted = "REDACTED"
 )
 
+//APIKeyHeader is the request header carrying the API key when the client is configured with WithAPIKeyHeader.
+const APIKeyHeader = "X-Api-Key"
+
 type Client struct {
-	client    *http.Client
-	apiKey    string
-	prefixURI string
-	timeOut   time.Duration
+	client       *http.Client
+	apiKey       string
+	apiKeyHeader string
+	prefixURI    string
+	timeOut      time.Duration
+	metrics      *Metrics
+	observer     Observer
+	keys         *KeyPool
+	usage        *Usage
+	breaker      *Breaker
+	endpoints    *Endpoints
+	normalizer   *Normalizer
+	ownership    *Ownership
+	classifier   *Classifier
 }
 
 //New creates a new client for maclookup.app API.
@@ -40,25 +60,130 @@ func (c *Client) WithAPIKey(apiKey string) {
 	c.apiKey = apiKey
 }
 
+//WithAPIKeyHeader sends the API key in the header name (APIKeyHeader when empty)
+//instead of the apiKey query parameter, so that it never appears in request URLs.
+func (c *Client) WithAPIKeyHeader(name string) {
+	if name == "" {
+		name = APIKeyHeader
+	}
+
+	c.apiKeyHeader = name
+}
+
 //WithTimeout defines a new timeout value for every request.
 func (c *Client) WithTimeout(timeout time.Duration) {
 	c.timeOut = timeout
 }
 
+//WithHTTPClient replaces the http.Client used for every request (e.g. to set a custom transport).
+func (c *Client) WithHTTPClient(client *http.Client) {
+	c.client = client
+}
+
 //WithPrefixURI changes the default API prefix url.
 func (c *Client) WithPrefixURI(prefixURI string) {
+	c.prefixURI = normalizePrefixURI(prefixURI)
+}
+
+func normalizePrefixURI(prefixURI string) string {
 	prefix := strings.TrimRight(prefixURI, "/")
 
 	if strings.HasPrefix(prefixURI, "http://") || strings.HasPrefix(prefixURI, "https://") {
-		c.prefixURI = prefix
-		return
+		return prefix
 	}
 
-	c.prefixURI = "https://" + prefix
-
 	if isIP(prefix) {
-		c.prefixURI = "http://" + prefix
+		return "http://" + prefix
+	}
+
+	return "https://" + prefix
+}
+
+//String describes the client without its API key.
+func (c Client) String() string {
+	key := "none"
+	if c.apiKey != "" {
+		key = redacted
+	}
+
+	if c.keys != nil {
+		key = fmt.Sprintf("%d pooled", c.keys.Len())
+	}
+
+	return fmt.Sprintf("maclookup.Client{prefixURI: %s, apiKey: %s, timeout: %s}", c.prefixURI, key, c.timeOut)
+}
+
+//GoString is like String, so that %#v never prints the API key.
+func (c Client) GoString() string {
+	return c.String()
+}
+
+// endpointURL returns the URL of an endpoint of the API at prefixURI for prefix,
+// with the API key when it is sent as a query parameter.
+func (c Client) endpointURL(prefixURI, prefix, suffix string) string {
+	u := prefixURI + apiMAC + prefix + suffix
+	if c.apiKey != "" && c.apiKeyHeader == "" {
+		u += apiKeyParam + c.apiKey
+	}
+
+	return u
+}
+
+func (c Client) newRequest(ctx context.Context, u string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", ua)
+	req.Header.Set("Accept", "*")
+
+	if c.apiKey != "" && c.apiKeyHeader != "" {
+		req.Header.Set(c.apiKeyHeader, c.apiKey)
 	}
+
+	return req, nil
+}
+
+// redact removes the API key from err, which can carry the request URL (e.g. *url.Error).
+func (c Client) redact(err error) error {
+	if err == nil || c.apiKey == "" {
+		return err
+	}
+
+	switch e := err.(type) {
+	case *HTTPClientError:
+		e.Err = c.redact(e.Err)
+	case *BadAPIRequest:
+		e.Err = c.redact(e.Err)
+	case *BadAPIKey:
+		e.Err = c.redact(e.Err)
+	case *BadAPIResponse:
+		e.Err = c.redact(e.Err)
+	case *url.Error:
+		e.URL = strings.Replace(e.URL, c.apiKey, redacted, -1)
+		e.Err = c.redact(e.Err)
+	default:
+		if strings.Contains(err.Error(), c.apiKey) {
+			return &redactedError{err: err, msg: strings.Replace(err.Error(), c.apiKey, redacted, -1)}
+		}
+	}
+
+	return err
+}
+
+// redactedError hides the API key from the message of an error and still unwraps to it.
+type redactedError struct {
+	err error
+	msg string
+}
+
+func (e *redactedError) Error() string {
+	return e.msg
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
 }
 
 func isIP(host string) bool {
@@ -100,15 +225,14 @@ func parseTimeHeader(header http.Header, property string) time.Time {
 	return time.Unix(parseInt, 0)
 }
 
-func cleanMac(mac string) string {
-	chars := []string{":", ".", "-", " "}
-	m := strings.TrimSpace(mac)
-
-	for _, c := range chars {
-		m = strings.Replace(m, c, "", -1)
-	}
+//Prefix returns the MAC prefix sent to the API for mac: separators removed, upper-cased and cut to
+//at most 36 bits. MACs with the same Prefix get the same answer, which makes it a cache key.
+func Prefix(mac string) string {
+	return cleanMac(mac)
+}
 
-	m = strings.ToUpper(m)
+func cleanMac(mac string) string {
+	m := macaddr.Clean(mac)
 
 	if len(m) >= 9 {
 		return m[0:9]
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 	"time"
 
@@ -59,6 +60,77 @@ func TestClient_WithTimeout(t *testing.T) {
 	assert.True(t, errors.As(err, &e))
 }
 
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestClient_WithHTTPClient(t *testing.T) {
+	called := false
+	client := New()
+	client.WithHTTPClient(&http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		called = true
+		return nil, errors.New("transport error")
+	})})
+
+	_, err := client.Lookup("000000")
+	assert.True(t, called)
+
+	var e *HTTPClientError
+
+	assert.True(t, errors.As(err, &e))
+}
+
+func TestClient_WithAPIKeyHeader(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v2/macs/000000/company/name", r.RequestURI)
+		assert.Equal(t, "SECRET_KEY", r.Header.Get(APIKeyHeader))
+		fmt.Fprint(w, `XEROX CORPORATION`)
+	}))
+	defer ts.Close()
+
+	client := New()
+	client.WithPrefixURI(ts.URL)
+	client.WithAPIKey("SECRET_KEY")
+	client.WithAPIKeyHeader("")
+
+	resp, err := client.CompanyName("000000")
+	assert.Nil(t, err)
+	assert.Equal(t, "XEROX CORPORATION", resp.Company)
+}
+
+func TestClient_RedactAPIKey(t *testing.T) {
+	client := New()
+	client.WithPrefixURI("http://127.0.0.1:1")
+	client.WithAPIKey("SECRET_KEY")
+
+	_, err := client.Lookup("000000")
+	assert.NotNil(t, err)
+	assert.NotContains(t, err.Error(), "SECRET_KEY")
+	assert.Contains(t, err.Error(), "apiKey=REDACTED")
+
+	var (
+		e  *HTTPClientError
+		ue *url.Error
+	)
+
+	assert.True(t, errors.As(err, &e))
+	assert.True(t, errors.As(err, &ue))
+
+	client.WithHTTPClient(&http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return nil, errors.New("refused " + r.URL.String())
+	})})
+
+	_, err = client.CompanyName("000000")
+	assert.NotContains(t, err.Error(), "SECRET_KEY")
+
+	for _, s := range []string{client.String(), fmt.Sprintf("%v", client), fmt.Sprintf("%+v", *client), fmt.Sprintf("%#v", client)} {
+		assert.NotContains(t, s, "SECRET_KEY")
+		assert.Contains(t, s, "REDACTED")
+	}
+}
+
 func Test_cleanMac(t *testing.T) {
 	type args struct {
 		mac string
@@ -134,3 +206,11 @@ func Test_cleanMac(t *testing.T) {
 		})
 	}
 }
+
+func TestPrefix(t *testing.T) {
+	for _, mac := range []string{"00:00:00:11:22:33", "00-00-00-11-22-34", "0000.0011.2235", "00 00 00 11 22 36", "000000112237"} {
+		assert.Equal(t, "000000112", Prefix(mac), mac)
+	}
+
+	assert.Equal(t, "AABBCC", Prefix("aa:bb:cc"))
+}
@@ -0,0 +1,139 @@
+//Command maclookup-leases prints the DHCP leases of dnsmasq, ISC dhcpd or Kea with the vendor of each MAC address.
+//
+//Usage:
+//	maclookup-leases [-format auto|dnsmasq|isc|kea] [-api-key KEY] [-full] [-all] [lease-file ...]
+//
+//Lease files are read from stdin when no file is given.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/logocomune/maclookup-go"
+	"github.com/logocomune/maclookup-go/leases"
+)
+
+func main() {
+	formatName := flag.String("format", "auto", "lease file format: auto, dnsmasq, isc or kea")
+	apiKey := flag.String("api-key", os.Getenv("MACLOOKUP_API_KEY"), "maclookup.app API key")
+	full := flag.Bool("full", false, "use the full lookup (country and block type) instead of the company name only")
+	all := flag.Bool("all", false, "include expired and inactive leases")
+	flag.Parse()
+
+	format, err := leases.ParseFormat(*formatName)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	list, err := readAll(format, flag.Args())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if !*all {
+		list = current(list, time.Now())
+	}
+
+	client := maclookup.New()
+	if *apiKey != "" {
+		client.WithAPIKey(*apiKey)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	defer w.Flush()
+
+	if *full {
+		entries, err := leases.EnrichInfo(list, client)
+
+		fmt.Fprintln(w, "IP\tMAC\tHOSTNAME\tEXPIRES\tCOMPANY\tCOUNTRY\tBLOCK")
+
+		for _, e := range entries {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", e.IP, e.MAC, e.Hostname, expires(e.Lease), e.Company, e.Country, e.BlockType)
+		}
+
+		if err != nil {
+			w.Flush()
+			log.Fatal(err)
+		}
+
+		return
+	}
+
+	entries, err := leases.Enrich(list, client)
+
+	fmt.Fprintln(w, "IP\tMAC\tHOSTNAME\tEXPIRES\tCOMPANY")
+
+	for _, e := range entries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", e.IP, e.MAC, e.Hostname, expires(e.Lease), company(e.CompanyInfo))
+	}
+
+	if err != nil {
+		w.Flush()
+		log.Fatal(err)
+	}
+}
+
+func readAll(format leases.Format, files []string) ([]leases.Lease, error) {
+	if len(files) == 0 {
+		return leases.Parse(format, os.Stdin)
+	}
+
+	var list []leases.Lease
+
+	for _, name := range files {
+		l, err := readFile(format, name)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		list = append(list, l...)
+	}
+
+	return list, nil
+}
+
+func readFile(format leases.Format, name string) ([]leases.Lease, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return leases.Parse(format, f)
+}
+
+func current(list []leases.Lease, now time.Time) []leases.Lease {
+	var out []leases.Lease
+
+	for _, l := range list {
+		if l.Active && !l.Expired(now) {
+			out = append(out, l)
+		}
+	}
+
+	return out
+}
+
+func expires(l leases.Lease) string {
+	if l.Expires.IsZero() {
+		return "never"
+	}
+
+	return l.Expires.Format(time.RFC3339)
+}
+
+func company(c maclookup.CompanyInfo) string {
+	switch {
+	case c.IsPrivate:
+		return maclookup.VendorPrivate
+	case !c.Found:
+		return "(unknown)"
+	}
+
+	return c.Company
+}
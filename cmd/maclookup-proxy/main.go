@@ -0,0 +1,55 @@
+//Command maclookup-proxy is a caching reverse proxy for the MACLookup API v2.
+//
+//Usage:
+//	maclookup-proxy [-listen :8080] [-api-key KEY] [-upstream URL] [-ttl 24h] [-max-entries 100000] [-timeout 5s] [-usage FILE]
+//
+//Clients use it with WithPrefixURI("http://proxy-host:8080").
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/logocomune/maclookup-go"
+	"github.com/logocomune/maclookup-go/proxy"
+)
+
+func main() {
+	listen := flag.String("listen", ":8080", "listen address")
+	apiKey := flag.String("api-key", os.Getenv("MACLOOKUP_API_KEY"), "maclookup.app API key used upstream")
+	upstream := flag.String("upstream", "", "upstream API prefix URI (default https://api.maclookup.app)")
+	ttl := flag.Duration("ttl", 24*time.Hour, "cache TTL")
+	maxEntries := flag.Int("max-entries", 100000, "maximum number of cached entries (0 for unbounded)")
+	timeout := flag.Duration("timeout", 5*time.Second, "upstream request timeout")
+	usageFile := flag.String("usage", "", "file recording the upstream requests (see maclookup-usage)")
+	flag.Parse()
+
+	client := maclookup.New()
+	client.WithTimeout(*timeout)
+
+	if *apiKey != "" {
+		client.WithAPIKey(*apiKey)
+	}
+
+	if *upstream != "" {
+		client.WithPrefixURI(*upstream)
+	}
+
+	if *usageFile != "" {
+		usage, err := maclookup.OpenUsage(*usageFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		client.WithUsage(usage)
+	}
+
+	p := proxy.New(client)
+	p.WithCache(*ttl, *maxEntries)
+
+	log.Printf("maclookup-proxy listening on %s", *listen)
+	log.Fatal(http.ListenAndServe(*listen, p))
+}
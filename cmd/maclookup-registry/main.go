@@ -0,0 +1,180 @@
+//Command maclookup-registry queries IEEE registry CSV files: it lists the matching blocks,
+//their statistics or the top companies by address space.
+//
+//Usage:
+//	maclookup-registry [-company NAME] [-match exact|prefix|substring|fuzzy] [-type MA-L,MA-M,MA-S,IAB,CID] [-country US,DE]
+//		[-since 2006-01-02] [-until 2006-01-02] [-min-size N] [-max-size N] [-stats] [-top N] [-json] oui.csv [mam.csv oui36.csv iab.csv cid.csv]
+//
+//Without -stats and -top the matching blocks are listed.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/logocomune/maclookup-go"
+	"github.com/logocomune/maclookup-go/registry"
+)
+
+var matchModes = map[string]registry.MatchMode{
+	"exact":     registry.MatchExact,
+	"prefix":    registry.MatchPrefix,
+	"substring": registry.MatchSubstring,
+	"fuzzy":     registry.MatchFuzzy,
+}
+
+func main() {
+	company := flag.String("company", "", "company name")
+	match := flag.String("match", "exact", "company matching: exact, prefix, substring or fuzzy")
+	types := flag.String("type", "", "comma separated block types")
+	countries := flag.String("country", "", "comma separated country codes")
+	since := flag.String("since", "", "blocks updated on or after this day (YYYY-MM-DD)")
+	until := flag.String("until", "", "blocks updated on or before this day (YYYY-MM-DD)")
+	minSize := flag.Int("min-size", 0, "minimum block size")
+	maxSize := flag.Int("max-size", 0, "maximum block size")
+	stats := flag.Bool("stats", false, "print the statistics of the matching blocks")
+	top := flag.Int("top", 0, "print the N companies with the most addresses")
+	asJSON := flag.Bool("json", false, "JSON output")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: maclookup-registry [flags] oui.csv [mam.csv oui36.csv iab.csv cid.csv]")
+		flag.PrintDefaults()
+		os.Exit(2)
+	}
+
+	mode, ok := matchModes[*match]
+	if !ok {
+		log.Fatalf("unknown match mode %q", *match)
+	}
+
+	q := registry.Query{
+		Company:      *company,
+		Match:        mode,
+		BlockTypes:   splitList(*types),
+		Countries:    splitList(*countries),
+		MinBlockSize: *minSize,
+		MaxBlockSize: *maxSize,
+	}
+
+	var err error
+
+	if q.UpdatedSince, err = parseDay(*since); err != nil {
+		log.Fatal(err)
+	}
+
+	if q.UpdatedUntil, err = parseDay(*until); err != nil {
+		log.Fatal(err)
+	}
+
+	reg, err := registry.LoadFiles(flag.Args()...)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	blocks := reg.Select(q, nil)
+
+	switch {
+	case *top > 0:
+		err = printTop(os.Stdout, registry.TopCompanies(blocks, *top), *asJSON)
+	case *stats:
+		err = printStats(os.Stdout, registry.Summarize(blocks), *asJSON)
+	default:
+		err = printBlocks(os.Stdout, blocks, *asJSON)
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	return strings.Split(s, ",")
+}
+
+func parseDay(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	return time.Parse(maclookup.UpdatedFormat, s)
+}
+
+func printBlocks(out io.Writer, blocks []maclookup.MACInfo, asJSON bool) error {
+	if asJSON {
+		return json.NewEncoder(out).Encode(blocks)
+	}
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "PREFIX\tTYPE\tSIZE\tCOUNTRY\tUPDATED\tCOMPANY")
+
+	for _, b := range blocks {
+		company := b.Company
+		if b.IsPrivate {
+			company = maclookup.VendorPrivate
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\n", b.MacPrefix, b.BlockType, b.BlockSize, b.Country, b.Updated, company)
+	}
+
+	return w.Flush()
+}
+
+func printStats(out io.Writer, s registry.Stats, asJSON bool) error {
+	if asJSON {
+		return json.NewEncoder(out).Encode(s)
+	}
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "BLOCKS\t%d\n", s.Total.Blocks)
+	fmt.Fprintf(w, "ADDRESSES\t%d\n", s.Total.Addresses)
+	fmt.Fprintf(w, "COMPANIES\t%d\n", s.Companies)
+	fmt.Fprintf(w, "PRIVATE BLOCKS\t%d\n", s.Private.Blocks)
+
+	printCounts(w, "TYPE", s.ByBlockType)
+	printCounts(w, "COUNTRY", s.ByCountry)
+
+	return w.Flush()
+}
+
+func printCounts(w io.Writer, name string, counts map[string]registry.Count) {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	fmt.Fprintf(w, "\n%s\tBLOCKS\tADDRESSES\n", name)
+
+	for _, k := range keys {
+		fmt.Fprintf(w, "%s\t%d\t%d\n", k, counts[k].Blocks, counts[k].Addresses)
+	}
+}
+
+func printTop(out io.Writer, top []registry.CompanyCount, asJSON bool) error {
+	if asJSON {
+		return json.NewEncoder(out).Encode(top)
+	}
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "COMPANY\tBLOCKS\tADDRESSES")
+
+	for _, c := range top {
+		fmt.Fprintf(w, "%s\t%d\t%d\n", c.Vendor.Name, c.Blocks, c.Addresses)
+	}
+
+	return w.Flush()
+}
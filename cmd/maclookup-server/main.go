@@ -0,0 +1,49 @@
+//Command maclookup-server serves the MACLookup API v2 from IEEE registry CSV files loaded at startup.
+//
+//Usage:
+//	maclookup-server [-listen :8080] [-api-keys KEY1,KEY2] [-rate-limit N] [-rate-window 1s] oui.csv [mam.csv oui36.csv iab.csv cid.csv]
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/logocomune/maclookup-go/registry"
+	"github.com/logocomune/maclookup-go/server"
+)
+
+func main() {
+	listen := flag.String("listen", ":8080", "listen address")
+	apiKeys := flag.String("api-keys", os.Getenv("MACLOOKUP_SERVER_API_KEYS"), "comma separated API keys (authentication disabled when empty)")
+	rateLimit := flag.Int64("rate-limit", 0, "requests allowed per API key every rate window (0 disables rate limiting)")
+	rateWindow := flag.Duration("rate-window", time.Second, "rate limit window")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		log.Fatal("at least one IEEE registry CSV file is required")
+	}
+
+	reg, err := registry.LoadFiles(flag.Args()...)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("loaded %d blocks", reg.Len())
+
+	s := server.New(reg)
+
+	if *apiKeys != "" {
+		s.WithAPIKeys(strings.Split(*apiKeys, ",")...)
+	}
+
+	if *rateLimit > 0 {
+		s.WithRateLimit(*rateLimit, *rateWindow)
+	}
+
+	log.Printf("maclookup-server listening on %s", *listen)
+	log.Fatal(http.ListenAndServe(*listen, s))
+}
@@ -0,0 +1,105 @@
+//Command maclookup-usage summarizes the requests recorded by a maclookup.Usage file.
+//
+//Usage:
+//	maclookup-usage [-from 2006-01-02] [-to 2006-01-02] [-days N] [-by day|endpoint|key|all] usage-file
+//
+//Without -from, -to and -days every recorded day is reported.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/logocomune/maclookup-go"
+)
+
+func main() {
+	fromDay := flag.String("from", "", "first day of the report (YYYY-MM-DD, UTC)")
+	toDay := flag.String("to", "", "last day of the report (YYYY-MM-DD, UTC)")
+	days := flag.Int("days", 0, "report the last N days, today included")
+	by := flag.String("by", "day", "group by day, endpoint or key, or list all the records")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: maclookup-usage [flags] usage-file")
+		flag.PrintDefaults()
+		os.Exit(2)
+	}
+
+	from, err := parseDay(*fromDay)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	to, err := parseDay(*toDay)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *days > 0 {
+		to = time.Now()
+		from = to.AddDate(0, 0, 1-*days)
+	}
+
+	if _, err := os.Stat(flag.Arg(0)); err != nil {
+		log.Fatal(err)
+	}
+
+	usage, err := maclookup.OpenUsage(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	report := usage.Report(from, to)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	defer w.Flush()
+
+	switch *by {
+	case "day":
+		printTotals(w, "DAY", report.ByDay)
+	case "endpoint":
+		printTotals(w, "ENDPOINT", report.ByEndpoint)
+	case "key":
+		printTotals(w, "KEY", report.ByKey)
+	case "all":
+		fmt.Fprintln(w, "DAY\tENDPOINT\tKEY\tREQUESTS\tERRORS")
+
+		for _, r := range report.Records {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\n", r.Day, r.Endpoint, r.Key, r.Requests, r.Errors)
+		}
+	default:
+		log.Fatalf("unknown grouping %q", *by)
+	}
+
+	fmt.Fprintf(w, "TOTAL\t%d\n", report.Total)
+	fmt.Fprintf(w, "ERRORS\t%d\n", report.Errors)
+}
+
+func parseDay(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	return time.Parse(maclookup.DayFormat, s)
+}
+
+func printTotals(w *tabwriter.Writer, name string, totals map[string]int64) {
+	keys := make([]string, 0, len(totals))
+	for k := range totals {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	fmt.Fprintf(w, "%s\tREQUESTS\n", name)
+
+	for _, k := range keys {
+		fmt.Fprintf(w, "%s\t%d\n", k, totals[k])
+	}
+}
@@ -0,0 +1,152 @@
+//Command maclookup-watch monitors the neighbors of the host (/proc/net/arp and "ip neigh") and reports
+//new devices, vendors never seen before, gone devices and MAC-to-IP changes.
+//
+//Usage:
+//	maclookup-watch [-interval 30s] [-gone 5m] [-arp /proc/net/arp] [-neigh=true] [-format text|json] [-sse :8081] [-state FILE] [-api-key KEY]
+//
+//Events are written to stdout as text or JSON Lines and, with -sse, streamed as Server-Sent Events on /events.
+//With -state, the devices and vendors seen are restored at startup and saved after every poll changing them.
+package main
+
+import (
+	"context"
+	"flag"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/logocomune/maclookup-go"
+	"github.com/logocomune/maclookup-go/watch"
+)
+
+func main() {
+	interval := flag.Duration("interval", watch.DefaultInterval, "delay between two reads of the neighbors")
+	gone := flag.Duration("gone", watch.DefaultGoneAfter, "delay after which a missing device is gone")
+	arp := flag.String("arp", watch.ProcARPPath, "ARP table file (empty to disable)")
+	neigh := flag.Bool("neigh", true, `read the neighbor table with "ip neigh show"`)
+	format := flag.String("format", "text", "output format: text or json (JSON Lines)")
+	sse := flag.String("sse", "", "listen address of the Server-Sent Events endpoint /events")
+	statePath := flag.String("state", "", "file keeping the devices and vendors seen across restarts")
+	apiKey := flag.String("api-key", os.Getenv("MACLOOKUP_API_KEY"), "maclookup.app API key")
+	flag.Parse()
+
+	write := watch.WriteText
+	switch *format {
+	case "text":
+	case "json":
+		write = watch.WriteJSON
+	default:
+		log.Fatalf("unknown format %q", *format)
+	}
+
+	var sources []watch.Source
+	if *arp != "" {
+		sources = append(sources, watch.ProcARP{Path: *arp})
+	}
+
+	if *neigh {
+		sources = append(sources, watch.IPNeigh{})
+	}
+
+	if len(sources) == 0 {
+		log.Fatal("no neighbor source: set -arp or -neigh")
+	}
+
+	client := maclookup.New()
+	if *apiKey != "" {
+		client.WithAPIKey(*apiKey)
+	}
+
+	client.WithNormalizer(maclookup.NewNormalizer())
+	client.WithClassifier(maclookup.DefaultClassifier())
+
+	w := watch.New(watch.Merge(sources...), client)
+	w.WithInterval(*interval)
+	w.WithGoneAfter(*gone)
+	w.OnError(func(err error) {
+		log.Printf("reading neighbors: %v", err)
+	})
+
+	if *statePath != "" {
+		if err := loadState(w, *statePath); err != nil {
+			log.Fatal(err)
+		}
+
+		w.OnChange(func() {
+			if err := saveState(w, *statePath); err != nil {
+				log.Printf("saving state: %v", err)
+			}
+		})
+	}
+
+	var broadcaster *watch.Broadcaster
+
+	if *sse != "" {
+		broadcaster = watch.NewBroadcaster()
+
+		mux := http.NewServeMux()
+		mux.Handle("/events", broadcaster)
+
+		go func() {
+			log.Fatal(http.ListenAndServe(*sse, mux))
+		}()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	_ = w.Run(ctx, func(e watch.Event) {
+		if err := write(os.Stdout, e); err != nil {
+			log.Fatal(err)
+		}
+
+		if broadcaster != nil {
+			broadcaster.Publish(e)
+		}
+	})
+
+	if *statePath != "" {
+		if err := saveState(w, *statePath); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+func loadState(w *watch.Watcher, path string) error {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return w.LoadState(f)
+}
+
+// saveState replaces the state file atomically.
+func saveState(w *watch.Watcher, path string) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if err := w.SaveState(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
@@ -12,28 +12,34 @@ import (
 
 //CompanyName returns company name from API.
 func (c Client) CompanyName(mac string) (ResponseVendorName, error) {
-	url := c.prefixURI + apiMAC + cleanMac(mac) + companyNameSuffix
-	if c.apiKey != "" {
-		url += apiKeyParam + c.apiKey
+	cl, err := c.begin(EndpointCompanyName, mac)
+	if err != nil {
+		return ResponseVendorName{}, err
 	}
 
-	return c.getCompanyName(url)
+	result, a, err := c.send(cl, func(ctx context.Context, prefixURI string, a *attempt) (interface{}, error) {
+		return c.getCompanyName(ctx, c.endpointURL(prefixURI, cl.event.Prefix, companyNameSuffix), a)
+	})
+	response, _ := result.(ResponseVendorName)
+	response.Annotations = c.annotate(mac, MACInfo{Found: response.Found, Company: response.Company, IsPrivate: response.IsPrivate})
+
+	err = c.end(cl, a, response.Found, response.IsPrivate, response.RespTime, response.RateLimit, err)
+
+	return response, err
 }
 
-func (c Client) getCompanyName(url string) (ResponseVendorName, error) {
+func (c Client) getCompanyName(ctx context.Context, url string, a *attempt) (ResponseVendorName, error) {
 	var response ResponseVendorName
 
 	start := time.Now()
-	timeout, cancell := context.WithTimeout(context.Background(), c.timeOut)
+	timeout, cancell := context.WithTimeout(ctx, c.timeOut)
 	defer cancell()
 
-	req, err := http.NewRequestWithContext(timeout, "GET", url, nil)
+	req, err := c.newRequest(timeout, url)
 	if err != nil {
 		return response, &HTTPClientError{Err: err}
 	}
 
-	req.Header.Set("User-Agent", ua)
-	req.Header.Set("Accept", "*")
 	resp, err := c.client.Do(req)
 
 	if err != nil {
@@ -47,24 +53,37 @@ func (c Client) getCompanyName(url string) (ResponseVendorName, error) {
 		Reset:     parseTimeHeader(resp.Header, xRateReset),
 	}
 
+	a.responseReceived(resp.StatusCode, response.RateLimit)
+
 	bodyBytes, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return response, &HTTPClientError{Err: err}
+		return response, &HTTPClientError{Err: err, ErrorResponse: newErrorResponse(resp, response.RateLimit)}
 	}
 
 	body := string(bodyBytes)
 	response.RespTime = time.Since(start)
 
-	statusCode := resp.StatusCode
+	if resp.StatusCode == http.StatusOK {
+		response.Found = !(body == "*NO COMPANY*")
+		response.IsPrivate = body == "*PRIVATE*"
+
+		if response.Found && !response.IsPrivate {
+			response.Company = body
+		}
 
-	switch statusCode {
+		return response, nil
+	}
+
+	er := newErrorResponse(resp, response.RateLimit).withTextBody(body)
+
+	switch er.StatusCode {
 	case http.StatusBadRequest:
 		msg := "client request error"
 		if body != "" {
 			msg = body
 		}
 
-		return response, &BadAPIRequest{Err: errors.New(strings.ToLower(msg))}
+		return response, &BadAPIRequest{Err: errors.New(strings.ToLower(msg)), ErrorResponse: er}
 
 	case http.StatusUnauthorized:
 		msg := "bad api key"
@@ -72,26 +91,19 @@ func (c Client) getCompanyName(url string) (ResponseVendorName, error) {
 			msg = body
 		}
 
-		return response, &BadAPIKey{Err: errors.New(msg)}
+		return response, &BadAPIKey{Err: errors.New(msg), ErrorResponse: er}
 
 	case http.StatusTooManyRequests:
 		return response, &RateLimitsExceeded{
-			Limit: response.RateLimit.Limit,
-			Reset: response.RateLimit.Reset,
+			Limit:         response.RateLimit.Limit,
+			Reset:         response.RateLimit.Reset,
+			Err:           ErrRateLimited,
+			ErrorResponse: er,
 		}
 
 	case http.StatusNotFound:
-		return response, &HTTPClientError{Err: errors.New("endpoint not found")}
-	case http.StatusOK:
-		response.Found = !(body == "*NO COMPANY*")
-		response.IsPrivate = body == "*PRIVATE*"
-
-		if response.Found && !response.IsPrivate {
-			response.Company = body
-		}
-
-		return response, nil
+		return response, &HTTPClientError{Err: ErrNotFound, ErrorResponse: er}
 	}
 
-	return response, &HTTPClientError{Err: errors.New("unexpected http status: " + strconv.Itoa(statusCode))}
+	return response, &HTTPClientError{Err: errors.New("unexpected http status: " + strconv.Itoa(er.StatusCode)), ErrorResponse: er}
 }
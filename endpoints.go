@@ -0,0 +1,290 @@
+package maclookup
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	defaultEndpointFailures   = 3
+	defaultEndpointRetryAfter = 30 * time.Second
+	latencySamples            = 100
+	minHedgeSamples           = 10
+)
+
+//Endpoints is an ordered list of API-compatible prefix URIs (the public API and its mirrors)
+//with health tracking. Requests go to the first healthy endpoint and fail over to the next ones
+//on transport errors and 5xx responses. An endpoint is unhealthy after consecutive failures,
+//and is tried again, first in order, after a delay. Unhealthy endpoints are still used as a last resort.
+//
+//With hedging, a second request is sent to the next endpoint when the first one
+//has not answered within a percentile of its recent latencies, and the first answer wins.
+type Endpoints struct {
+	mu         sync.Mutex
+	endpoints  []*endpoint
+	failures   int
+	retryAfter time.Duration
+	percentile float64
+	minDelay   time.Duration
+	now        func() time.Time
+}
+
+type endpoint struct {
+	prefixURI string
+	failures  int
+	downUntil time.Time
+	latencies []time.Duration
+	next      int
+}
+
+//EndpointStatus is the health of an endpoint.
+type EndpointStatus struct {
+	PrefixURI string
+	Healthy   bool
+	Failures  int
+	DownUntil time.Time
+	//Latency is the median latency of the recent successful requests.
+	Latency time.Duration
+}
+
+//NewEndpoints creates an endpoint list. Prefix URIs are normalized as by WithPrefixURI.
+//An endpoint becomes unhealthy after 3 consecutive failures, for 30 seconds.
+func NewEndpoints(prefixURIs ...string) *Endpoints {
+	e := &Endpoints{
+		failures:   defaultEndpointFailures,
+		retryAfter: defaultEndpointRetryAfter,
+		now:        time.Now,
+	}
+
+	for _, p := range prefixURIs {
+		e.endpoints = append(e.endpoints, &endpoint{prefixURI: normalizePrefixURI(p)})
+	}
+
+	return e
+}
+
+//WithHealth sets the number of consecutive failures making an endpoint unhealthy and for how long.
+func (e *Endpoints) WithHealth(failures int, retryAfter time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if failures < 1 {
+		failures = 1
+	}
+
+	e.failures = failures
+	e.retryAfter = retryAfter
+}
+
+//WithHedging enables hedged requests after the given percentile (e.g. 0.95) of the latencies
+//of the endpoint, and not before minDelay. Hedging starts once an endpoint has 10 latency samples.
+//A percentile of 0 disables hedging.
+func (e *Endpoints) WithHedging(percentile float64, minDelay time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.percentile = percentile
+	e.minDelay = minDelay
+}
+
+//Status returns the health of every endpoint, in the configured order.
+func (e *Endpoints) Status() []EndpointStatus {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.now()
+	status := make([]EndpointStatus, 0, len(e.endpoints))
+
+	for _, ep := range e.endpoints {
+		status = append(status, EndpointStatus{
+			PrefixURI: ep.prefixURI,
+			Healthy:   !now.Before(ep.downUntil),
+			Failures:  ep.failures,
+			DownUntil: ep.downUntil,
+			Latency:   ep.percentile(0.5),
+		})
+	}
+
+	return status
+}
+
+//WithEndpoints sends the requests to e instead of the prefix URI set with WithPrefixURI.
+func (c *Client) WithEndpoints(e *Endpoints) {
+	c.endpoints = e
+}
+
+// order returns the healthy endpoints in the configured order, then the unhealthy ones by recovery time.
+func (e *Endpoints) order() []*endpoint {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.now()
+	order := make([]*endpoint, 0, len(e.endpoints))
+
+	var down []*endpoint
+
+	for _, ep := range e.endpoints {
+		if now.Before(ep.downUntil) {
+			down = append(down, ep)
+			continue
+		}
+
+		order = append(order, ep)
+	}
+
+	sort.SliceStable(down, func(i, j int) bool { return down[i].downUntil.Before(down[j].downUntil) })
+
+	return append(order, down...)
+}
+
+// report records the result of a request sent to ep.
+func (e *Endpoints) report(ep *endpoint, latency time.Duration, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if isServerFailure(err) {
+		ep.failures++
+		if ep.failures >= e.failures {
+			ep.downUntil = e.now().Add(e.retryAfter)
+		}
+
+		return
+	}
+
+	ep.failures = 0
+	ep.downUntil = time.Time{}
+
+	if len(ep.latencies) < latencySamples {
+		ep.latencies = append(ep.latencies, latency)
+		return
+	}
+
+	ep.latencies[ep.next] = latency
+	ep.next = (ep.next + 1) % latencySamples
+}
+
+// hedgeDelay returns how long to wait for ep before hedging, or 0 to not hedge.
+func (e *Endpoints) hedgeDelay(ep *endpoint) time.Duration {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.percentile <= 0 || len(ep.latencies) < minHedgeSamples {
+		return 0
+	}
+
+	d := ep.percentile(e.percentile)
+	if d < e.minDelay {
+		d = e.minDelay
+	}
+
+	return d
+}
+
+func (ep *endpoint) percentile(p float64) time.Duration {
+	if len(ep.latencies) == 0 {
+		return 0
+	}
+
+	sorted := append([]time.Duration(nil), ep.latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	i := int(p * float64(len(sorted)))
+	if i >= len(sorted) {
+		i = len(sorted) - 1
+	}
+
+	return sorted[i]
+}
+
+type attemptResult struct {
+	ep      *endpoint
+	attempt *attempt
+	value   interface{}
+	err     error
+	latency time.Duration
+}
+
+// send runs get for cl against the client prefix URI, or against its endpoints with failover and hedging.
+// Every request is a new attempt of cl: send returns the one whose result is returned.
+func (c Client) send(cl *call, get func(ctx context.Context, prefixURI string, a *attempt) (interface{}, error)) (interface{}, *attempt, error) {
+	var order []*endpoint
+	if c.endpoints != nil {
+		order = c.endpoints.order()
+	}
+
+	if len(order) == 0 {
+		a := cl.attempt()
+		v, err := get(context.Background(), c.prefixURI, a)
+
+		return v, a, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Buffered so that the attempts still running when send returns never block.
+	results := make(chan attemptResult, len(order))
+	running, next := 0, 0
+
+	launch := func() {
+		ep := order[next]
+		next++
+		running++
+
+		go func() {
+			a := cl.attempt()
+			start := time.Now()
+			v, err := get(ctx, ep.prefixURI, a)
+			results <- attemptResult{ep: ep, attempt: a, value: v, err: err, latency: time.Since(start)}
+		}()
+	}
+
+	launch()
+
+	var last attemptResult
+
+	for running > 0 {
+		var (
+			hedge *time.Timer
+			fire  <-chan time.Time
+		)
+
+		if running == 1 && next < len(order) {
+			if d := c.endpoints.hedgeDelay(order[next-1]); d > 0 {
+				hedge = time.NewTimer(d)
+				fire = hedge.C
+			}
+		}
+
+		select {
+		case r := <-results:
+			running--
+			c.endpoints.report(r.ep, r.latency, r.err)
+
+			if !isServerFailure(r.err) {
+				stopTimer(hedge)
+				return r.value, r.attempt, r.err
+			}
+
+			last = r
+
+			if running == 0 && next < len(order) {
+				launch()
+			}
+		case <-fire:
+			launch()
+		}
+
+		stopTimer(hedge)
+	}
+
+	return last.value, last.attempt, last.err
+}
+
+func stopTimer(t *time.Timer) {
+	if t != nil {
+		t.Stop()
+	}
+}
@@ -0,0 +1,170 @@
+package maclookup
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newEndpointServer(status *int32, delay *int64, hits *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(hits, 1)
+
+		select {
+		case <-time.After(time.Duration(atomic.LoadInt64(delay))):
+		case <-r.Context().Done():
+			return
+		}
+
+		w.WriteHeader(int(atomic.LoadInt32(status)))
+		fmt.Fprintln(w, `{"success":true,"found":true,"macPrefix":"000000","company":"XEROX CORPORATION"}`)
+	}))
+}
+
+func TestClient_WithEndpointsFailover(t *testing.T) {
+	var (
+		primaryStatus, mirrorStatus int32 = http.StatusServiceUnavailable, http.StatusOK
+		primaryDelay, mirrorDelay   int64
+		primaryHits, mirrorHits     int32
+	)
+
+	primary := newEndpointServer(&primaryStatus, &primaryDelay, &primaryHits)
+	defer primary.Close()
+
+	mirror := newEndpointServer(&mirrorStatus, &mirrorDelay, &mirrorHits)
+	defer mirror.Close()
+
+	now := time.Now()
+	endpoints := NewEndpoints(primary.URL+"/", mirror.URL)
+	endpoints.now = func() time.Time { return now }
+	endpoints.WithHealth(2, time.Minute)
+
+	client := New()
+	client.WithEndpoints(endpoints)
+
+	for i := 0; i < 3; i++ {
+		resp, err := client.Lookup("000000")
+		assert.Nil(t, err)
+		assert.Equal(t, "XEROX CORPORATION", resp.Company)
+	}
+
+	// The primary is skipped once unhealthy.
+	assert.Equal(t, int32(2), atomic.LoadInt32(&primaryHits))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&mirrorHits))
+
+	status := endpoints.Status()
+	assert.Equal(t, primary.URL, status[0].PrefixURI)
+	assert.False(t, status[0].Healthy)
+	assert.Equal(t, now.Add(time.Minute), status[0].DownUntil)
+	assert.True(t, status[1].Healthy)
+
+	// Every endpoint fails: the last error is returned.
+	atomic.StoreInt32(&mirrorStatus, http.StatusBadGateway)
+	_, err := client.CompanyName("000000")
+	assert.True(t, IsRetryable(err))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&primaryHits))
+
+	// The primary is first again once recovered.
+	now = now.Add(2 * time.Minute)
+	atomic.StoreInt32(&primaryStatus, http.StatusOK)
+	_, err = client.Lookup("000000")
+	assert.Nil(t, err)
+	assert.Equal(t, int32(4), atomic.LoadInt32(&primaryHits))
+	assert.True(t, endpoints.Status()[0].Healthy)
+}
+
+func TestClient_WithEndpointsHedging(t *testing.T) {
+	var (
+		primaryStatus, mirrorStatus int32 = http.StatusOK, http.StatusOK
+		primaryDelay, mirrorDelay   int64
+		primaryHits, mirrorHits     int32
+	)
+
+	primary := newEndpointServer(&primaryStatus, &primaryDelay, &primaryHits)
+	defer primary.Close()
+
+	mirror := newEndpointServer(&mirrorStatus, &mirrorDelay, &mirrorHits)
+	defer mirror.Close()
+
+	endpoints := NewEndpoints(primary.URL, mirror.URL)
+	endpoints.WithHedging(0.9, 20*time.Millisecond)
+
+	client := New()
+	client.WithEndpoints(endpoints)
+
+	for i := 0; i < minHedgeSamples; i++ {
+		_, err := client.Lookup("000000")
+		assert.Nil(t, err)
+	}
+
+	assert.Equal(t, int32(0), atomic.LoadInt32(&mirrorHits))
+
+	atomic.StoreInt64(&primaryDelay, int64(2*time.Second))
+
+	start := time.Now()
+	resp, err := client.Lookup("000000")
+	assert.Nil(t, err)
+	assert.Equal(t, "XEROX CORPORATION", resp.Company)
+	assert.True(t, time.Since(start) < time.Second)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&mirrorHits))
+}
+
+func TestClient_WithEndpointsHedging_reportsWinner(t *testing.T) {
+	var warm int32 = 1
+
+	respond := func(status int, remaining string, headerDelay, bodyDelay time.Duration) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if atomic.LoadInt32(&warm) == 0 {
+				time.Sleep(headerDelay)
+			}
+
+			w.Header().Set(xRateRemaining, remaining)
+			w.WriteHeader(status)
+			w.(http.Flusher).Flush()
+
+			if atomic.LoadInt32(&warm) == 0 {
+				select {
+				case <-time.After(bodyDelay):
+				case <-r.Context().Done():
+					return
+				}
+			}
+
+			fmt.Fprintln(w, `{"success":true,"found":true,"macPrefix":"000000","company":"XEROX CORPORATION"}`)
+		}
+	}
+
+	// After the warm-up, the primary sends its headers after the mirror, but never its body.
+	primary := httptest.NewServer(respond(http.StatusOK, "1", 100*time.Millisecond, time.Hour))
+	defer primary.Close()
+
+	mirror := httptest.NewServer(respond(http.StatusOK, "99", 0, 300*time.Millisecond))
+	defer mirror.Close()
+
+	endpoints := NewEndpoints(primary.URL, mirror.URL)
+	endpoints.WithHedging(0.9, 20*time.Millisecond)
+
+	var done RequestEvent
+
+	client := New()
+	client.WithEndpoints(endpoints)
+	client.WithObserver(ObserverFuncs{OnDone: func(e RequestEvent) { done = e }})
+
+	for i := 0; i < minHedgeSamples; i++ {
+		_, err := client.Lookup("000000")
+		assert.Nil(t, err)
+	}
+
+	atomic.StoreInt32(&warm, 0)
+
+	resp, err := client.Lookup("000000")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(99), resp.RateLimit.Remaining)
+	assert.Equal(t, http.StatusOK, done.StatusCode)
+	assert.Equal(t, int64(99), done.RateLimit.Remaining)
+}
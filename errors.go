@@ -1,12 +1,63 @@
 package maclookup
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"strings"
 	"time"
 )
 
+//Sentinel errors matched with errors.Is by the errors returned by Lookup and CompanyName.
+var (
+	//ErrNotFound is wrapped by the HTTPClientError of an API endpoint not found (HTTP 404).
+	//A MAC address not found in the database is not an error: see Found.
+	ErrNotFound = errors.New("endpoint not found")
+	//ErrUnauthorized is matched by BadAPIKey.
+	ErrUnauthorized = errors.New("unauthorized")
+	//ErrRateLimited is matched by RateLimitsExceeded.
+	ErrRateLimited = errors.New("rate limited")
+)
+
+const moreInfoSeparator = " - more info: "
+
+//ErrorResponse is the API response that caused an error. It is the zero value
+//for errors raised before a response was received.
+type ErrorResponse struct {
+	StatusCode int
+	//ErrorCode and MoreInfo are the errorCode and moreInfo of the API error body, when available.
+	ErrorCode int
+	MoreInfo  string
+	//Message is the error message sent by the API, as is.
+	Message   string
+	RateLimit RateLimit
+	Header    http.Header
+}
+
+func newErrorResponse(resp *http.Response, rl RateLimit) ErrorResponse {
+	return ErrorResponse{
+		StatusCode: resp.StatusCode,
+		RateLimit:  rl,
+		Header:     resp.Header.Clone(),
+	}
+}
+
+// withTextBody sets Message and MoreInfo from a plain text error body ("message - more info: url").
+func (r ErrorResponse) withTextBody(body string) ErrorResponse {
+	body = strings.TrimSpace(body)
+	r.Message = body
+
+	if i := strings.Index(body, moreInfoSeparator); i >= 0 {
+		r.MoreInfo = strings.TrimSpace(body[i+len(moreInfoSeparator):])
+	}
+
+	return r
+}
+
 type HTTPClientError struct {
 	Err error
+	ErrorResponse
 }
 
 func (c *HTTPClientError) Error() string {
@@ -19,6 +70,7 @@ func (c *HTTPClientError) Unwrap() error {
 
 type BadAPIRequest struct {
 	Err error
+	ErrorResponse
 }
 
 func (c *BadAPIRequest) Error() string {
@@ -31,6 +83,7 @@ func (c *BadAPIRequest) Unwrap() error {
 
 type BadAPIKey struct {
 	Err error
+	ErrorResponse
 }
 
 func (c *BadAPIKey) Error() string {
@@ -41,18 +94,34 @@ func (c *BadAPIKey) Unwrap() error {
 	return c.Err
 }
 
+//Is reports whether target is ErrUnauthorized.
+func (c *BadAPIKey) Is(target error) bool {
+	return target == ErrUnauthorized
+}
+
 type RateLimitsExceeded struct {
 	Limit int64
 	Reset time.Time
 	Err   error
+	ErrorResponse
 }
 
 func (c *RateLimitsExceeded) Error() string {
 	return fmt.Sprintf("rate limits exceded. current limit is %d. next reset %s", c.Limit, c.Reset.Format(time.RFC3339))
 }
 
+func (c *RateLimitsExceeded) Unwrap() error {
+	return c.Err
+}
+
+//Is reports whether target is ErrRateLimited.
+func (c *RateLimitsExceeded) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
 type BadAPIResponse struct {
 	Err error
+	ErrorResponse
 }
 
 func (c *BadAPIResponse) Error() string {
@@ -62,3 +131,28 @@ func (c *BadAPIResponse) Error() string {
 func (c *BadAPIResponse) Unwrap() error {
 	return c.Err
 }
+
+//IsRetryable reports whether the request that returned err can be sent again later:
+//rate limits, open circuit breakers, timeouts, transport errors and server errors.
+//Invalid requests, bad API keys, missing endpoints and canceled requests are not retryable.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var (
+		rateErr      *RateLimitsExceeded
+		openErr      *CircuitOpenError
+		transportErr *HTTPClientError
+	)
+
+	switch {
+	case errors.As(err, &rateErr), errors.As(err, &openErr):
+		return true
+	case errors.As(err, &transportErr):
+		code := transportErr.StatusCode
+		return code == 0 || code == http.StatusRequestTimeout || code >= http.StatusInternalServerError
+	}
+
+	return false
+}
@@ -0,0 +1,122 @@
+package maclookup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_LookupErrorResponse(t *testing.T) {
+	now := time.Now()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add(xRateLimit, "2, 2;window=1")
+		w.Header().Add(xRateRemaining, "0")
+		w.Header().Add(xRateReset, fmt.Sprintf("%d", now.Unix()))
+		w.Header().Add("X-Request-Id", "abc")
+
+		switch r.URL.Path {
+		case "/v2/macs/0000":
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintln(w, `{"success":false,"error":"MAC must be greater than 5 chars","errorCode":101,"moreInfo":"https://maclookup.app/api-v2/documentation"}`)
+		case "/v2/macs/000000":
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprintln(w, `{"success":false,"error":"Too Many Requests","errorCode":429,"moreInfo":"https://maclookup.app/api-v2/rate-limits"}`)
+		case "/v2/macs/010000":
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprintln(w, `{"success":false,"error":"Unauthorized","errorCode":401,"moreInfo":"https://maclookup.app/api-v2/plans"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	client := New()
+	client.WithPrefixURI(ts.URL)
+
+	_, err := client.Lookup("0000")
+
+	var requestErr *BadAPIRequest
+
+	assert.True(t, errors.As(err, &requestErr))
+	assert.Equal(t, "mac must be greater than 5 chars", requestErr.Error())
+	assert.Equal(t, "MAC must be greater than 5 chars", requestErr.Message)
+	assert.Equal(t, http.StatusBadRequest, requestErr.StatusCode)
+	assert.Equal(t, 101, requestErr.ErrorCode)
+	assert.Equal(t, "https://maclookup.app/api-v2/documentation", requestErr.MoreInfo)
+	assert.Equal(t, "abc", requestErr.Header.Get("X-Request-Id"))
+	assert.False(t, IsRetryable(err))
+
+	_, err = client.Lookup("000000")
+
+	var rateErr *RateLimitsExceeded
+
+	assert.True(t, errors.As(err, &rateErr))
+	assert.True(t, errors.Is(err, ErrRateLimited))
+	assert.Equal(t, ErrRateLimited, errors.Unwrap(err))
+	assert.Equal(t, 429, rateErr.ErrorCode)
+	assert.Equal(t, RateLimit{Limit: 2, Remaining: 0, Reset: time.Unix(now.Unix(), 0)}, rateErr.RateLimit)
+	assert.True(t, IsRetryable(err))
+
+	_, err = client.Lookup("010000")
+	assert.True(t, errors.Is(err, ErrUnauthorized))
+	assert.False(t, errors.Is(err, ErrRateLimited))
+	assert.False(t, IsRetryable(err))
+
+	_, err = client.Lookup("020000")
+	assert.True(t, errors.Is(err, ErrNotFound))
+	assert.False(t, IsRetryable(err))
+}
+
+func TestClient_CompanyNameErrorResponse(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/macs/000000/company/name":
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `Bad APIKey - Unauthorized - more info: https://maclookup.app/api-v2/plans`)
+		case "/v2/macs/010000/company/name":
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	client := New()
+	client.WithPrefixURI(ts.URL)
+
+	_, err := client.CompanyName("000000")
+
+	var keyErr *BadAPIKey
+
+	assert.True(t, errors.As(err, &keyErr))
+	assert.True(t, errors.Is(err, ErrUnauthorized))
+	assert.Equal(t, http.StatusUnauthorized, keyErr.StatusCode)
+	assert.Equal(t, "https://maclookup.app/api-v2/plans", keyErr.MoreInfo)
+
+	_, err = client.CompanyName("010000")
+
+	var transportErr *HTTPClientError
+
+	assert.True(t, errors.As(err, &transportErr))
+	assert.Equal(t, http.StatusBadGateway, transportErr.StatusCode)
+	assert.True(t, IsRetryable(err))
+
+	_, err = client.CompanyName("020000")
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
+
+func TestIsRetryable(t *testing.T) {
+	assert.False(t, IsRetryable(nil))
+	assert.True(t, IsRetryable(&HTTPClientError{Err: errors.New("connection refused")}))
+	assert.True(t, IsRetryable(&HTTPClientError{Err: context.DeadlineExceeded}))
+	assert.False(t, IsRetryable(&HTTPClientError{Err: context.Canceled}))
+	assert.True(t, IsRetryable(fmt.Errorf("wrapped: %w", &RateLimitsExceeded{})))
+	assert.True(t, errors.Is(&RateLimitsExceeded{}, ErrRateLimited))
+	assert.False(t, IsRetryable(&BadAPIResponse{Err: errors.New("e")}))
+}
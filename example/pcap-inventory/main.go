@@ -0,0 +1,39 @@
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/logocomune/maclookup-go"
+	"github.com/logocomune/maclookup-go/pcap"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		log.Fatal("usage: pcap-inventory <capture.pcap|capture.pcapng>")
+	}
+
+	f, err := os.Open(os.Args[1])
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	inv, err := pcap.ReadInventory(f)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	report, err := inv.Resolve(maclookup.New())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, h := range report.Hosts {
+		log.Printf("%s %-40s frames=%d first=%s last=%s ips=%v", h.MAC, h.Vendor, h.Frames(), h.FirstSeen, h.LastSeen, h.IPs)
+	}
+
+	for _, v := range report.Vendors {
+		log.Printf("%-40s hosts=%d frames=%d", v.Vendor, v.Hosts, v.Frames)
+	}
+}
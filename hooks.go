@@ -0,0 +1,167 @@
+package maclookup
+
+import (
+	"sync"
+	"time"
+)
+
+//RequestEvent describes a request at one stage of its lifecycle.
+//StatusCode and RateLimit are set once the response headers are received;
+//Duration, Outcome and Err once the request is completed.
+type RequestEvent struct {
+	Endpoint   string
+	Prefix     string
+	Start      time.Time
+	StatusCode int
+	Duration   time.Duration
+	RateLimit  RateLimit
+	Outcome    string
+	Err        error
+}
+
+//Observer is notified of every request sent by a Client.
+//Start is called before the request is sent and returns the RequestObserver
+//notified of the rest of the lifecycle of that request, so that per-request
+//state such as a tracing span can be kept in it. Start may return nil.
+type Observer interface {
+	Start(e RequestEvent) RequestObserver
+}
+
+//RequestObserver receives the events of a single request.
+type RequestObserver interface {
+	ResponseReceived(e RequestEvent)
+	Done(e RequestEvent)
+}
+
+//ObserverFuncs adapts plain functions to Observer. Nil functions are skipped.
+type ObserverFuncs struct {
+	OnStart    func(e RequestEvent)
+	OnResponse func(e RequestEvent)
+	OnDone     func(e RequestEvent)
+}
+
+//Start implements Observer.
+func (o ObserverFuncs) Start(e RequestEvent) RequestObserver {
+	if o.OnStart != nil {
+		o.OnStart(e)
+	}
+
+	return o
+}
+
+//ResponseReceived implements RequestObserver.
+func (o ObserverFuncs) ResponseReceived(e RequestEvent) {
+	if o.OnResponse != nil {
+		o.OnResponse(e)
+	}
+}
+
+//Done implements RequestObserver.
+func (o ObserverFuncs) Done(e RequestEvent) {
+	if o.OnDone != nil {
+		o.OnDone(e)
+	}
+}
+
+//WithObserver notifies o of the lifecycle of every request.
+func (c *Client) WithObserver(o Observer) {
+	c.observer = o
+}
+
+// call tracks a single request for observers, metrics and usage.
+// Hedged requests send several attempts: only the one whose result is returned is reported by done.
+type call struct {
+	mu       sync.Mutex
+	finished bool
+	event    RequestEvent
+	trace    RequestObserver
+	metrics  *Metrics
+	usage    *Usage
+	apiKey   string
+	// generation is the breaker generation admitting the request.
+	generation uint64
+}
+
+// attempt is the response state of one of the requests sent for a call.
+type attempt struct {
+	call       *call
+	statusCode int
+	rateLimit  RateLimit
+}
+
+func (c Client) startCall(endpoint, prefix string) *call {
+	cl := &call{
+		event:   RequestEvent{Endpoint: endpoint, Prefix: prefix, Start: time.Now()},
+		metrics: c.metrics,
+		usage:   c.usage,
+		apiKey:  c.apiKey,
+	}
+
+	if c.observer != nil {
+		cl.trace = c.observer.Start(cl.event)
+	}
+
+	return cl
+}
+
+func (cl *call) attempt() *attempt {
+	return &attempt{call: cl}
+}
+
+// responseReceived notifies the observer of the response headers of a, unless the call is done.
+func (a *attempt) responseReceived(statusCode int, rl RateLimit) {
+	cl := a.call
+
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	a.statusCode = statusCode
+	a.rateLimit = rl
+
+	if cl.finished || cl.trace == nil {
+		return
+	}
+
+	e := cl.event
+	e.StatusCode = statusCode
+	e.RateLimit = rl
+	e.Duration = time.Since(e.Start)
+
+	cl.trace.ResponseReceived(e)
+}
+
+// done reports the result of a, the attempt whose result is returned.
+func (cl *call) done(a *attempt, found, private bool, respTime time.Duration, err error) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	cl.finished = true
+	cl.event.StatusCode = a.statusCode
+	cl.event.RateLimit = a.rateLimit
+	cl.event.Duration = elapsed(respTime, cl.event.Start)
+	cl.event.Outcome = Outcome(found, private, err)
+	cl.event.Err = err
+
+	cl.metrics.observe(cl.event.Endpoint, cl.event.Outcome, cl.event.Duration, cl.event.RateLimit)
+	cl.usage.record(cl.event.Endpoint, cl.apiKey, cl.event.RateLimit, err)
+
+	if cl.trace != nil {
+		cl.trace.Done(cl.event)
+	}
+}
+
+// reject reports a request rejected by the circuit breaker, which was not sent.
+func (cl *call) reject(err error) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	cl.finished = true
+	cl.event.Outcome = Outcome(false, false, err)
+	cl.event.Err = err
+
+	cl.metrics.reject(cl.event.Endpoint, cl.event.Outcome)
+
+	if cl.trace != nil {
+		cl.trace.Done(cl.event)
+	}
+}
@@ -0,0 +1,83 @@
+package maclookup
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingObserver struct {
+	events []string
+	last   RequestEvent
+}
+
+func (o *recordingObserver) Start(e RequestEvent) RequestObserver {
+	o.events = append(o.events, "start "+e.Endpoint+" "+e.Prefix)
+	return o
+}
+
+func (o *recordingObserver) ResponseReceived(e RequestEvent) {
+	o.events = append(o.events, fmt.Sprintf("response %d", e.StatusCode))
+}
+
+func (o *recordingObserver) Done(e RequestEvent) {
+	o.events = append(o.events, "done "+e.Outcome)
+	o.last = e
+}
+
+func TestClient_WithObserver(t *testing.T) {
+	now := time.Now()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add(xRateLimit, "10")
+		w.Header().Add(xRateRemaining, "7")
+		w.Header().Add(xRateReset, fmt.Sprintf("%d", now.Unix()))
+
+		switch r.URL.Path {
+		case "/v2/macs/000000112":
+			fmt.Fprintln(w, `{"success":true,"found":true,"macPrefix":"000000","company":"XEROX CORPORATION","blockType":"MA-L"}`)
+		default:
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer ts.Close()
+
+	o := &recordingObserver{}
+	client := New()
+	client.WithPrefixURI(ts.URL)
+	client.WithObserver(o)
+
+	_, err := client.Lookup("00:00:00:11:22:33")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"start lookup 000000112", "response 200", "done ok"}, o.events)
+	assert.Equal(t, http.StatusOK, o.last.StatusCode)
+	assert.Equal(t, RateLimit{Limit: 10, Remaining: 7, Reset: time.Unix(now.Unix(), 0)}, o.last.RateLimit)
+	assert.True(t, o.last.Duration > 0)
+	assert.False(t, o.last.Start.IsZero())
+
+	o.events = nil
+	_, err = client.CompanyName("ACDE48")
+	assert.NotNil(t, err)
+	assert.Equal(t, []string{"start company_name ACDE48", "response 429", "done rate_limited"}, o.events)
+	assert.Equal(t, err, o.last.Err)
+}
+
+func TestClient_WithObserverTransportError(t *testing.T) {
+	var events []RequestEvent
+
+	client := New()
+	client.WithPrefixURI("http://127.0.0.1:1")
+	client.WithObserver(ObserverFuncs{
+		OnDone: func(e RequestEvent) { events = append(events, e) },
+	})
+
+	_, err := client.Lookup("000000")
+	assert.NotNil(t, err)
+	assert.Len(t, events, 1)
+	assert.Equal(t, 0, events[0].StatusCode)
+	assert.Equal(t, OutcomeTransportError, events[0].Outcome)
+	assert.Equal(t, err, events[0].Err)
+}
@@ -0,0 +1,224 @@
+// Package apiv2 holds the wire format of the MACLookup API v2 shared by the
+// server side packages of this module (test server, proxy and self-hosted server).
+package apiv2
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/logocomune/maclookup-go"
+	"github.com/logocomune/maclookup-go/internal/macaddr"
+)
+
+const (
+	PathMACs          = "/v2/macs/"
+	CompanyNameSuffix = "/company/name"
+	APIKeyParam       = "apiKey"
+	APIKeyHeader      = maclookup.APIKeyHeader
+
+	HeaderRateLimit     = "X-RateLimit-Limit"
+	HeaderRateRemaining = "X-RateLimit-Remaining"
+	HeaderRateReset     = "X-RateLimit-Reset"
+
+	NoCompany = "*NO COMPANY*"
+	Private   = "*PRIVATE*"
+
+	MoreInfoDocumentation = "https://maclookup.app/api-v2/documentation"
+	MoreInfoPlans         = "https://maclookup.app/api-v2/plans"
+	MoreInfoRateLimits    = "https://maclookup.app/api-v2/rate-limits"
+
+	ErrorCodeShortMAC   = 101
+	ErrorCodeInvalidMAC = 102
+)
+
+type MACResponse struct {
+	Success    bool   `json:"success"`
+	Found      bool   `json:"found"`
+	MacPrefix  string `json:"macPrefix"`
+	Company    string `json:"company"`
+	Address    string `json:"address"`
+	Country    string `json:"country"`
+	BlockStart string `json:"blockStart"`
+	BlockEnd   string `json:"blockEnd"`
+	BlockSize  int    `json:"blockSize"`
+	BlockType  string `json:"blockType"`
+	Updated    string `json:"updated"`
+	IsRand     bool   `json:"isRand"`
+	IsPrivate  bool   `json:"isPrivate"`
+}
+
+type NotFoundResponse struct {
+	Success bool `json:"success"`
+	Found   bool `json:"found"`
+	IsRand  bool `json:"isRand"`
+}
+
+type ErrorResponse struct {
+	Success   bool   `json:"success"`
+	Error     string `json:"error,omitempty"`
+	ErrorCode int    `json:"errorCode,omitempty"`
+	MoreInfo  string `json:"moreInfo,omitempty"`
+}
+
+//Request is a parsed request to one of the v2 endpoints.
+type Request struct {
+	MAC         string
+	CompanyName bool
+	APIKey      string
+}
+
+//ParseRequest extracts the MAC, the endpoint and the API key of r.
+//The API key is read from the apiKey query parameter, then from the APIKeyHeader header.
+//It returns false when the path is not a v2 MAC endpoint.
+func ParseRequest(r *http.Request) (Request, bool) {
+	if !strings.HasPrefix(r.URL.Path, PathMACs) {
+		return Request{}, false
+	}
+
+	req := Request{
+		MAC:    strings.TrimPrefix(r.URL.Path, PathMACs),
+		APIKey: r.URL.Query().Get(APIKeyParam),
+	}
+
+	if req.APIKey == "" {
+		req.APIKey = r.Header.Get(APIKeyHeader)
+	}
+
+	if strings.HasSuffix(req.MAC, CompanyNameSuffix) {
+		req.MAC = strings.TrimSuffix(req.MAC, CompanyNameSuffix)
+		req.CompanyName = true
+	}
+
+	if req.MAC == "" || strings.Contains(req.MAC, "/") {
+		return Request{}, false
+	}
+
+	return req, true
+}
+
+//CleanMAC removes separators and upper-cases mac, as the client does before sending it.
+func CleanMAC(mac string) string {
+	return macaddr.Clean(mac)
+}
+
+//ValidateMAC returns the 400 error body for an invalid MAC, or nil.
+func ValidateMAC(mac string) *ErrorResponse {
+	if len(mac) < 6 {
+		return &ErrorResponse{Error: "MAC must be greater than 5 chars", ErrorCode: ErrorCodeShortMAC, MoreInfo: MoreInfoDocumentation}
+	}
+
+	for _, c := range mac {
+		if !strings.ContainsRune("0123456789ABCDEF", c) {
+			return &ErrorResponse{Error: "MAC must contain only hexadecimal chars", ErrorCode: ErrorCodeInvalidMAC, MoreInfo: MoreInfoDocumentation}
+		}
+	}
+
+	return nil
+}
+
+//IsRand reports whether mac (at least two hex digits) is locally administered.
+func IsRand(mac string) bool {
+	if len(mac) < 2 {
+		return false
+	}
+
+	b, err := strconv.ParseUint(mac[:2], 16, 8)
+
+	return err == nil && b&0x02 != 0
+}
+
+//SetRateLimit writes the X-RateLimit-* headers in the format used by the API.
+func SetRateLimit(h http.Header, limit, remaining int64, window time.Duration, reset time.Time) {
+	h.Set(HeaderRateLimit, fmt.Sprintf("%d, %d;window=%d", limit, limit, int64(window/time.Second)))
+	h.Set(HeaderRateRemaining, strconv.FormatInt(remaining, 10))
+	h.Set(HeaderRateReset, strconv.FormatInt(reset.Unix(), 10))
+}
+
+//WriteJSON writes v with the given status.
+func WriteJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+//WriteText writes a plain text body with the given status.
+func WriteText(w http.ResponseWriter, status int, body string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(body))
+}
+
+//WriteError writes an error in the format of the endpoint: JSON for the MAC lookup,
+//plain text for the company name.
+func WriteError(w http.ResponseWriter, companyName bool, status int, e ErrorResponse) {
+	if companyName {
+		msg := e.Error
+
+		switch status {
+		case http.StatusBadRequest:
+		case http.StatusUnauthorized:
+			msg = "Bad APIKey - " + msg + " - more info: " + e.MoreInfo
+		default:
+			msg += " - more info: " + e.MoreInfo
+		}
+
+		WriteText(w, status, msg)
+
+		return
+	}
+
+	e.Success = false
+	WriteJSON(w, status, e)
+}
+
+//Unauthorized is the error returned for a missing or invalid API key.
+func Unauthorized() ErrorResponse {
+	return ErrorResponse{Error: "Unauthorized", ErrorCode: http.StatusUnauthorized, MoreInfo: MoreInfoPlans}
+}
+
+//TooManyRequests is the error returned when the rate limit is exceeded.
+func TooManyRequests() ErrorResponse {
+	return ErrorResponse{Error: "Too Many Requests", ErrorCode: http.StatusTooManyRequests, MoreInfo: MoreInfoRateLimits}
+}
+
+//WriteMACInfo writes the successful answer for info in the format of the endpoint.
+//mac is the cleaned MAC of the request, used for the isRand flag.
+func WriteMACInfo(w http.ResponseWriter, companyName bool, mac string, info maclookup.MACInfo) {
+	if companyName {
+		switch {
+		case !info.Found:
+			WriteText(w, http.StatusOK, NoCompany)
+		case info.IsPrivate:
+			WriteText(w, http.StatusOK, Private)
+		default:
+			WriteText(w, http.StatusOK, info.Company)
+		}
+
+		return
+	}
+
+	if !info.Found {
+		WriteJSON(w, http.StatusOK, NotFoundResponse{Success: true, IsRand: IsRand(mac)})
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, MACResponse{
+		Success:    true,
+		Found:      true,
+		MacPrefix:  info.MacPrefix,
+		Company:    info.Company,
+		Address:    info.Address,
+		Country:    info.Country,
+		BlockStart: info.BlockStart,
+		BlockEnd:   info.BlockEnd,
+		BlockSize:  info.BlockSize,
+		BlockType:  info.BlockType,
+		Updated:    info.Updated,
+		IsRand:     IsRand(mac),
+		IsPrivate:  info.IsPrivate,
+	})
+}
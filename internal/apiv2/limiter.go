@@ -0,0 +1,63 @@
+package apiv2
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+//Limiter is a fixed window rate limiter keyed by API key.
+type Limiter struct {
+	mu      sync.Mutex
+	limit   int64
+	window  time.Duration
+	now     func() time.Time
+	windows map[string]*fixedWindow
+}
+
+type fixedWindow struct {
+	reset time.Time
+	used  int64
+}
+
+//NewLimiter allows limit requests per key in every window.
+func NewLimiter(limit int64, window time.Duration) *Limiter {
+	return &Limiter{
+		limit:   limit,
+		window:  window,
+		now:     time.Now,
+		windows: make(map[string]*fixedWindow),
+	}
+}
+
+//Allow consumes a request for key and writes the X-RateLimit-* headers to h.
+//It returns false when the key has no requests left in the current window.
+func (l *Limiter) Allow(key string, h http.Header) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+
+	w, ok := l.windows[key]
+	if !ok || !now.Before(w.reset) {
+		w = &fixedWindow{reset: now.Truncate(time.Second).Add(l.window)}
+		l.windows[key] = w
+	}
+
+	allowed := w.used < l.limit
+	if allowed {
+		w.used++
+	}
+
+	SetRateLimit(h, l.limit, l.limit-w.used, l.window, w.reset)
+
+	return allowed
+}
+
+//Reset forgets the usage of every key.
+func (l *Limiter) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.windows = make(map[string]*fixedWindow)
+}
@@ -0,0 +1,12 @@
+// Package macaddr holds the MAC address parsing shared by the root package and the
+// packages of this module that cannot import it.
+package macaddr
+
+import "strings"
+
+var separators = strings.NewReplacer(":", "", "-", "", ".", "", " ", "")
+
+//Clean removes the ":", "-", "." and " " separators from mac and upper-cases it.
+func Clean(mac string) string {
+	return strings.ToUpper(separators.Replace(strings.TrimSpace(mac)))
+}
@@ -0,0 +1,239 @@
+package maclookup
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+//ErrNoAPIKey is returned when every key of a KeyPool has been disabled.
+var ErrNoAPIKey = errors.New("no api key available")
+
+// defaultLimitedFor is how long a key is skipped after a 429 without X-RateLimit-Reset.
+const defaultLimitedFor = time.Minute
+
+//KeyPool is a set of API keys shared by one or more clients.
+//Every request uses the key with the most remaining requests in the current rate limit window,
+//keys that are rate limited are skipped until their reset, and keys rejected by the API are disabled.
+type KeyPool struct {
+	mu         sync.Mutex
+	keys       []*poolKey
+	onDisabled func(label string, err error)
+	now        func() time.Time
+}
+
+type poolKey struct {
+	key          string
+	rateLimit    RateLimit
+	known        bool
+	limitedUntil time.Time
+	disabled     bool
+	// spent is the number of requests taken from rateLimit.Remaining by pick since the last update.
+	spent int64
+}
+
+//KeyStatus is the state of a key of a KeyPool, identified by its KeyLabel.
+type KeyStatus struct {
+	Label        string
+	RateLimit    RateLimit
+	LimitedUntil time.Time
+	Disabled     bool
+}
+
+//NewKeyPool creates a pool of keys. Keys are tried in order until their rate limits are known.
+func NewKeyPool(keys ...string) *KeyPool {
+	p := &KeyPool{now: time.Now}
+
+	for _, k := range keys {
+		if k != "" {
+			p.keys = append(p.keys, &poolKey{key: k})
+		}
+	}
+
+	return p
+}
+
+//OnDisabled sets the function called with the KeyLabel of a key disabled after a BadAPIKey error.
+func (p *KeyPool) OnDisabled(f func(label string, err error)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.onDisabled = f
+}
+
+//Status returns the state of every key, in the order they were added.
+func (p *KeyPool) Status() []KeyStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	status := make([]KeyStatus, 0, len(p.keys))
+	for _, k := range p.keys {
+		status = append(status, KeyStatus{Label: KeyLabel(k.key), RateLimit: k.rateLimit, LimitedUntil: k.limitedUntil, Disabled: k.disabled})
+	}
+
+	return status
+}
+
+//Len returns the number of keys.
+func (p *KeyPool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return len(p.keys)
+}
+
+//WithKeyPool sends every request with a key of p instead of the key set with WithAPIKey.
+func (c *Client) WithKeyPool(p *KeyPool) {
+	c.keys = p
+}
+
+// pick returns the key with the most remaining requests.
+// It fails with RateLimitsExceeded, reset at the earliest reset of the pool, when every key is rate limited.
+func (p *KeyPool) pick() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+
+	var (
+		best      *poolKey
+		bestScore int64
+		reset     time.Time
+	)
+
+	for _, k := range p.keys {
+		if k.disabled {
+			continue
+		}
+
+		if now.Before(k.limitedUntil) {
+			if reset.IsZero() || k.limitedUntil.Before(reset) {
+				reset = k.limitedUntil
+			}
+
+			continue
+		}
+
+		score := k.remaining(now)
+		if best == nil || score > bestScore {
+			best, bestScore = k, score
+		}
+	}
+
+	if best == nil {
+		if reset.IsZero() {
+			return "", ErrNoAPIKey
+		}
+
+		return "", &RateLimitsExceeded{Limit: -1, Reset: reset, Err: ErrRateLimited}
+	}
+
+	// Spread concurrent requests until the response updates the budget.
+	if best.known && best.rateLimit.Remaining > 0 {
+		best.rateLimit.Remaining--
+		best.spent++
+	}
+
+	return best.key, nil
+}
+
+// refund gives back the request taken by pick from the budget of key, for a request that was not sent.
+func (p *KeyPool) refund(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, k := range p.keys {
+		if k.key == key && k.spent > 0 {
+			k.spent--
+			k.rateLimit.Remaining++
+		}
+	}
+}
+
+// remaining is the budget of k: unknown budgets and budgets past their reset are considered full.
+func (k *poolKey) remaining(now time.Time) int64 {
+	if !k.known || !now.Before(k.rateLimit.Reset) {
+		return math.MaxInt64
+	}
+
+	return k.rateLimit.Remaining
+}
+
+// update records the outcome of a request sent with key.
+func (p *KeyPool) update(key string, rl RateLimit, err error) {
+	p.mu.Lock()
+
+	var k *poolKey
+
+	for _, pk := range p.keys {
+		if pk.key == key {
+			k = pk
+			break
+		}
+	}
+
+	if k == nil {
+		p.mu.Unlock()
+		return
+	}
+
+	if rl.Remaining >= 0 && !rl.Reset.IsZero() {
+		k.rateLimit = rl
+		k.known = true
+		k.spent = 0
+	}
+
+	var (
+		rateErr    *RateLimitsExceeded
+		keyErr     *BadAPIKey
+		onDisabled func(label string, err error)
+	)
+
+	switch {
+	case errors.As(err, &rateErr):
+		k.limitedUntil = rateErr.Reset
+		if !k.limitedUntil.After(p.now()) {
+			k.limitedUntil = p.now().Add(defaultLimitedFor)
+		}
+	case errors.As(err, &keyErr) && !k.disabled:
+		k.disabled = true
+		onDisabled = p.onDisabled
+	}
+
+	p.mu.Unlock()
+
+	if onDisabled != nil {
+		onDisabled(KeyLabel(key), err)
+	}
+}
+
+// usePoolKey sets the API key of the current request from the pool, if any.
+func (c *Client) usePoolKey() error {
+	if c.keys == nil {
+		return nil
+	}
+
+	key, err := c.keys.pick()
+	if err != nil {
+		return err
+	}
+
+	c.apiKey = key
+
+	return nil
+}
+
+// refundPoolKey gives back to the pool, if any, the budget taken for a request that was not sent.
+func (c Client) refundPoolKey() {
+	if c.keys != nil {
+		c.keys.refund(c.apiKey)
+	}
+}
+
+// releasePoolKey records the outcome of the current request in the pool, if any.
+func (c Client) releasePoolKey(rl RateLimit, err error) {
+	if c.keys != nil {
+		c.keys.update(c.apiKey, rl, err)
+	}
+}
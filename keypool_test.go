@@ -0,0 +1,137 @@
+package maclookup
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_WithKeyPool(t *testing.T) {
+	reset := time.Now().Add(time.Hour).Unix()
+	remaining := map[string]int{"A": 2, "B": 5}
+
+	var used []string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.URL.Query().Get("apiKey")
+		used = append(used, key)
+
+		rem, ok := remaining[key]
+		if !ok {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprintln(w, `{"success":false,"error":"Unauthorized","errorCode":401,"moreInfo":"https://maclookup.app/api-v2/plans"}`)
+
+			return
+		}
+
+		w.Header().Add(xRateLimit, "10")
+		w.Header().Add(xRateRemaining, fmt.Sprintf("%d", rem))
+		w.Header().Add(xRateReset, fmt.Sprintf("%d", reset))
+
+		if strings.HasSuffix(r.URL.Path, "/company/name") {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		fmt.Fprintln(w, `{"success":true,"found":false}`)
+	}))
+	defer ts.Close()
+
+	var disabled []string
+
+	pool := NewKeyPool("A", "B", "C")
+	pool.OnDisabled(func(label string, err error) {
+		disabled = append(disabled, label)
+
+		assert.True(t, errors.Is(err, ErrUnauthorized))
+	})
+
+	client := New()
+	client.WithPrefixURI(ts.URL)
+	client.WithKeyPool(pool)
+
+	_, err := client.Lookup("000000")
+	assert.Nil(t, err)
+	_, err = client.Lookup("000000")
+	assert.Nil(t, err)
+	_, err = client.Lookup("000000")
+	assert.True(t, errors.Is(err, ErrUnauthorized))
+	assert.Equal(t, []string{KeyLabel("C")}, disabled)
+
+	// B has the most remaining requests, until it is rate limited.
+	_, err = client.CompanyName("000000")
+	assert.True(t, errors.Is(err, ErrRateLimited))
+	_, err = client.Lookup("000000")
+	assert.Nil(t, err)
+
+	assert.Equal(t, []string{"A", "B", "C", "B", "A"}, used)
+
+	status := pool.Status()
+	assert.Len(t, status, 3)
+	assert.Equal(t, KeyLabel("A"), status[0].Label)
+	assert.Equal(t, time.Unix(reset, 0), status[1].LimitedUntil)
+	assert.True(t, status[2].Disabled)
+	assert.Equal(t, int64(2), status[0].RateLimit.Remaining)
+	assert.Contains(t, client.String(), "3 pooled")
+}
+
+func TestKeyPool_pick(t *testing.T) {
+	now := time.Now()
+	pool := NewKeyPool("A", "B")
+	pool.now = func() time.Time { return now }
+
+	pool.update("A", RateLimit{Limit: 10, Remaining: 0, Reset: now.Add(time.Second)}, &RateLimitsExceeded{})
+	pool.update("B", RateLimit{Limit: 10, Remaining: 0, Reset: now.Add(time.Minute)}, &RateLimitsExceeded{Reset: now.Add(time.Minute)})
+
+	_, err := pool.pick()
+
+	var e *RateLimitsExceeded
+
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, now.Add(time.Minute), e.Reset)
+
+	now = now.Add(2 * time.Minute)
+	key, err := pool.pick()
+	assert.Nil(t, err)
+	assert.Equal(t, "A", key)
+
+	pool.update("A", RateLimit{}, &BadAPIKey{Err: errors.New("bad api key")})
+	pool.update("B", RateLimit{}, &BadAPIKey{Err: errors.New("bad api key")})
+
+	_, err = pool.pick()
+	assert.Equal(t, ErrNoAPIKey, err)
+}
+
+func TestClient_WithKeyPoolBreakerOpen(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add(xRateLimit, "10")
+		w.Header().Add(xRateRemaining, "5")
+		w.Header().Add(xRateReset, fmt.Sprintf("%d", time.Now().Add(time.Hour).Unix()))
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	pool := NewKeyPool("A")
+
+	client := New()
+	client.WithPrefixURI(ts.URL)
+	client.WithKeyPool(pool)
+	client.WithBreaker(NewBreaker(1, time.Hour))
+
+	_, err := client.Lookup("000000")
+	assert.False(t, errors.Is(err, ErrCircuitOpen))
+
+	// Requests rejected by the open circuit do not use the budget of the key.
+	for i := 0; i < 3; i++ {
+		_, err = client.Lookup("000000")
+		assert.True(t, errors.Is(err, ErrCircuitOpen))
+	}
+
+	assert.Equal(t, int64(5), pool.Status()[0].RateLimit.Remaining)
+}
@@ -0,0 +1,62 @@
+package leases
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//ParseDnsmasq reads a dnsmasq lease file.
+//DHCPv6 entries, which carry a DUID instead of a MAC address, are skipped.
+func ParseDnsmasq(r io.Reader) ([]Lease, error) {
+	var leases []Lease
+
+	sc := bufio.NewScanner(r)
+	lineNo := 0
+
+	for sc.Scan() {
+		lineNo++
+
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 || fields[0] == "duid" {
+			continue
+		}
+
+		if len(fields) < 4 {
+			return leases, fmt.Errorf("leases: dnsmasq line %d: expected at least 4 fields", lineNo)
+		}
+
+		mac, err := net.ParseMAC(fields[1])
+		if err != nil {
+			continue
+		}
+
+		ip := net.ParseIP(fields[2])
+		if ip == nil {
+			return leases, fmt.Errorf("leases: dnsmasq line %d: invalid ip %q", lineNo, fields[2])
+		}
+
+		expiry, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			return leases, fmt.Errorf("leases: dnsmasq line %d: invalid expiry %q", lineNo, fields[0])
+		}
+
+		l := Lease{IP: ip, MAC: mac, Active: true}
+
+		if expiry > 0 {
+			l.Expires = time.Unix(expiry, 0)
+		}
+
+		if fields[3] != "*" {
+			l.Hostname = fields[3]
+		}
+
+		leases = append(leases, l)
+	}
+
+	return leases, sc.Err()
+}
@@ -0,0 +1,3 @@
+// Package leases parses DHCP server lease files (dnsmasq, ISC dhcpd and Kea memfile)
+// and joins them with the vendor information returned by the MACLookup API.
+package leases
@@ -0,0 +1,152 @@
+package leases
+
+import (
+	"encoding/json"
+	"net"
+
+	"github.com/logocomune/maclookup-go"
+)
+
+//Entry is a lease joined with its company name.
+type Entry struct {
+	Lease
+	maclookup.CompanyInfo
+}
+
+//InfoEntry is a lease joined with its full MAC information.
+type InfoEntry struct {
+	Lease
+	maclookup.MACInfo
+}
+
+// The JSON encodings flatten the lease and the API fields in a single object, with MAC as "aa:bb:cc:dd:ee:ff":
+// the json.Marshaler promoted from the maclookup types would encode only the API fields.
+type (
+	leaseFields       Lease
+	companyInfoFields maclookup.CompanyInfo
+	macInfoFields     maclookup.MACInfo
+)
+
+type entryJSON struct {
+	leaseFields
+	MAC string
+	companyInfoFields
+}
+
+type infoEntryJSON struct {
+	leaseFields
+	MAC string
+	macInfoFields
+}
+
+//MarshalJSON implements json.Marshaler.
+func (e Entry) MarshalJSON() ([]byte, error) {
+	return json.Marshal(entryJSON{leaseFields(e.Lease), e.MAC.String(), companyInfoFields(e.CompanyInfo)})
+}
+
+//UnmarshalJSON implements json.Unmarshaler.
+func (e *Entry) UnmarshalJSON(data []byte) error {
+	var v entryJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	l, err := v.leaseFields.lease(v.MAC)
+	if err != nil {
+		return err
+	}
+
+	*e = Entry{l, maclookup.CompanyInfo(v.companyInfoFields)}
+
+	return nil
+}
+
+//MarshalJSON implements json.Marshaler.
+func (e InfoEntry) MarshalJSON() ([]byte, error) {
+	return json.Marshal(infoEntryJSON{leaseFields(e.Lease), e.MAC.String(), macInfoFields(e.MACInfo)})
+}
+
+//UnmarshalJSON implements json.Unmarshaler.
+func (e *InfoEntry) UnmarshalJSON(data []byte) error {
+	var v infoEntryJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	l, err := v.leaseFields.lease(v.MAC)
+	if err != nil {
+		return err
+	}
+
+	*e = InfoEntry{l, maclookup.MACInfo(v.macInfoFields)}
+
+	return nil
+}
+
+// lease returns the Lease of f with the MAC address mac.
+func (f leaseFields) lease(mac string) (Lease, error) {
+	l := Lease(f)
+	if mac == "" {
+		return l, nil
+	}
+
+	hw, err := net.ParseMAC(mac)
+	if err != nil {
+		return Lease{}, err
+	}
+
+	l.MAC = hw
+
+	return l, nil
+}
+
+//Enrich resolves the company name of every lease, calling r once per maclookup.Prefix.
+//When r fails, the entries of the leases before the failing one are returned with the error.
+func Enrich(leases []Lease, r maclookup.CompanyNameResolver) ([]Entry, error) {
+	entries := make([]Entry, 0, len(leases))
+	cache := make(map[string]maclookup.CompanyInfo)
+
+	for _, l := range leases {
+		key := maclookup.Prefix(l.MAC.String())
+
+		info, ok := cache[key]
+		if !ok {
+			resp, err := r.CompanyName(l.MAC.String())
+			if err != nil {
+				return entries, err
+			}
+
+			info = resp.CompanyInfo
+			cache[key] = info
+		}
+
+		entries = append(entries, Entry{Lease: l, CompanyInfo: info})
+	}
+
+	return entries, nil
+}
+
+//EnrichInfo is like Enrich, with the full MAC information of every lease.
+func EnrichInfo(leases []Lease, r maclookup.MACInfoResolver) ([]InfoEntry, error) {
+	entries := make([]InfoEntry, 0, len(leases))
+	cache := make(map[string]maclookup.MACInfo)
+
+	for _, l := range leases {
+		key := maclookup.Prefix(l.MAC.String())
+
+		info, ok := cache[key]
+		if !ok {
+			resp, err := r.Lookup(l.MAC.String())
+			if err != nil {
+				return entries, err
+			}
+
+			info = resp.MACInfo
+			cache[key] = info
+		}
+
+		entries = append(entries, InfoEntry{Lease: l, MACInfo: info})
+	}
+
+	return entries, nil
+}
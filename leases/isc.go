@@ -0,0 +1,175 @@
+package leases
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const iscTimeLayout = "2006/01/02 15:04:05"
+
+//ParseISC reads an ISC dhcpd dhcpd.leases file.
+//dhcpd appends a new declaration every time a lease changes, so only the last declaration of each address is kept.
+func ParseISC(r io.Reader) ([]Lease, error) {
+	var (
+		order   []string
+		byIP    = make(map[string]Lease)
+		current *Lease
+		depth   int
+	)
+
+	sc := bufio.NewScanner(r)
+	lineNo := 0
+
+	for sc.Scan() {
+		lineNo++
+
+		line := strings.TrimSpace(stripComment(sc.Text()))
+		if line == "" {
+			continue
+		}
+
+		if current == nil {
+			if strings.HasPrefix(line, "lease ") && strings.HasSuffix(line, "{") {
+				ip := net.ParseIP(strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(line, "lease "), "{")))
+				if ip == nil {
+					return nil, fmt.Errorf("leases: isc line %d: invalid lease address", lineNo)
+				}
+
+				current = &Lease{IP: ip}
+				depth = 1
+
+				continue
+			}
+
+			// Skip other top level declarations, including nested blocks.
+			depth += strings.Count(line, "{") - strings.Count(line, "}")
+
+			continue
+		}
+
+		if strings.HasSuffix(line, "{") {
+			depth++
+			continue
+		}
+
+		if line == "}" {
+			depth--
+			if depth == 0 {
+				key := current.IP.String()
+				if _, seen := byIP[key]; !seen {
+					order = append(order, key)
+				}
+
+				byIP[key] = *current
+				current = nil
+			}
+
+			continue
+		}
+
+		if err := current.iscStatement(strings.TrimSuffix(line, ";")); err != nil {
+			return nil, fmt.Errorf("leases: isc line %d: %w", lineNo, err)
+		}
+	}
+
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	if current != nil {
+		return nil, fmt.Errorf("leases: isc: unterminated lease %s", current.IP)
+	}
+
+	leases := make([]Lease, 0, len(order))
+
+	for _, key := range order {
+		if l := byIP[key]; l.MAC != nil {
+			leases = append(leases, l)
+		}
+	}
+
+	return leases, nil
+}
+
+func (l *Lease) iscStatement(stmt string) error {
+	fields := strings.Fields(stmt)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	switch {
+	case fields[0] == "ends":
+		t, err := parseISCTime(fields[1:])
+		if err != nil {
+			return err
+		}
+
+		l.Expires = t
+	case fields[0] == "binding" && len(fields) == 3 && fields[1] == "state":
+		l.Active = fields[2] == "active"
+	case fields[0] == "hardware" && len(fields) == 3:
+		mac, err := net.ParseMAC(fields[2])
+		if err != nil {
+			return err
+		}
+
+		l.MAC = mac
+	case fields[0] == "client-hostname" && len(fields) >= 2:
+		l.Hostname = unquote(strings.TrimSpace(strings.TrimPrefix(stmt, "client-hostname")))
+	}
+
+	return nil
+}
+
+// parseISCTime parses "never", "epoch <seconds>" and "<weekday> <yyyy/mm/dd> <hh:mm:ss>" (UTC).
+func parseISCTime(fields []string) (time.Time, error) {
+	switch {
+	case len(fields) == 1 && fields[0] == "never":
+		return time.Time{}, nil
+	case len(fields) == 2 && fields[0] == "epoch":
+		sec, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid epoch %q", fields[1])
+		}
+
+		return time.Unix(sec, 0), nil
+	case len(fields) == 3:
+		return time.Parse(iscTimeLayout, fields[1]+" "+fields[2])
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time %q", strings.Join(fields, " "))
+}
+
+func stripComment(line string) string {
+	inQuote, escaped := false, false
+
+	for i, c := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case c == '\\' && inQuote:
+			escaped = true
+		case c == '"':
+			inQuote = !inQuote
+		case c == '#':
+			if !inQuote {
+				return line[:i]
+			}
+		}
+	}
+
+	return line
+}
+
+func unquote(s string) string {
+	if u, err := strconv.Unquote(s); err == nil {
+		return u
+	}
+
+	return strings.Trim(s, `"`)
+}
@@ -0,0 +1,108 @@
+package leases
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const keaStateDefault = "0"
+
+//ParseKea reads a Kea memfile lease CSV (DHCPv4 or DHCPv6).
+//Columns are located by the header, and rows without a hardware address are skipped.
+//As with ISC, Kea appends updated leases, so only the last row of each address is kept.
+func ParseKea(r io.Reader) ([]Lease, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.Comment = '#'
+
+	header, err := cr.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("leases: kea header: %w", err)
+	}
+
+	cols := make(map[string]int, len(header))
+	for i, name := range header {
+		cols[strings.TrimSpace(name)] = i
+	}
+
+	for _, required := range []string{"address", "hwaddr"} {
+		if _, ok := cols[required]; !ok {
+			return nil, fmt.Errorf("leases: kea header is missing column %q", required)
+		}
+	}
+
+	field := func(row []string, name string) string {
+		if i, ok := cols[name]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+
+		return ""
+	}
+
+	var order []string
+
+	byIP := make(map[string]Lease)
+
+	for {
+		row, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("leases: kea: %w", err)
+		}
+
+		ip := net.ParseIP(field(row, "address"))
+		if ip == nil {
+			continue
+		}
+
+		key := ip.String()
+		if _, seen := byIP[key]; !seen {
+			order = append(order, key)
+		}
+
+		mac, err := net.ParseMAC(field(row, "hwaddr"))
+		if err != nil {
+			// A later row without hwaddr still replaces the previous state of the address.
+			byIP[key] = Lease{IP: ip}
+			continue
+		}
+
+		l := Lease{
+			IP:       ip,
+			MAC:      mac,
+			Hostname: strings.ReplaceAll(field(row, "hostname"), "&#x2c", ","),
+		}
+
+		state := field(row, "state")
+		l.Active = state == "" || state == keaStateDefault
+
+		if exp, err := strconv.ParseInt(field(row, "expire"), 10, 64); err == nil && exp > 0 {
+			l.Expires = time.Unix(exp, 0)
+		}
+
+		byIP[key] = l
+	}
+
+	leases := make([]Lease, 0, len(order))
+
+	for _, key := range order {
+		if l := byIP[key]; l.MAC != nil {
+			leases = append(leases, l)
+		}
+	}
+
+	return leases, nil
+}
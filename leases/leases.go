@@ -0,0 +1,134 @@
+package leases
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+//Format is a DHCP server lease file format.
+type Format int
+
+//Supported lease file formats.
+const (
+	FormatAuto Format = iota
+	FormatDnsmasq
+	FormatISC
+	FormatKea
+)
+
+var formatNames = map[Format]string{
+	FormatAuto:    "auto",
+	FormatDnsmasq: "dnsmasq",
+	FormatISC:     "isc",
+	FormatKea:     "kea",
+}
+
+func (f Format) String() string {
+	if name, ok := formatNames[f]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("Format(%d)", int(f))
+}
+
+//ParseFormat returns the Format named name (auto, dnsmasq, isc or kea).
+func ParseFormat(name string) (Format, error) {
+	for f, n := range formatNames {
+		if strings.EqualFold(n, name) {
+			return f, nil
+		}
+	}
+
+	return FormatAuto, fmt.Errorf("leases: unknown format %q", name)
+}
+
+//ErrUnknownFormat is returned by Parse when FormatAuto cannot recognise the input.
+var ErrUnknownFormat = errors.New("leases: unable to detect lease file format")
+
+//Lease is a single DHCP lease.
+//Expires is zero when the lease never expires or the format does not record it.
+type Lease struct {
+	IP       net.IP
+	MAC      net.HardwareAddr
+	Hostname string
+	Expires  time.Time
+	Active   bool
+}
+
+//Expired reports whether the lease expired before now.
+func (l Lease) Expired(now time.Time) bool {
+	return !l.Expires.IsZero() && l.Expires.Before(now)
+}
+
+//Parse reads leases in the given format. FormatAuto detects the format from the content.
+func Parse(format Format, r io.Reader) ([]Lease, error) {
+	br := bufio.NewReader(r)
+
+	if format == FormatAuto {
+		head, _ := br.Peek(4096)
+
+		format = detect(head)
+		if format == FormatAuto {
+			return nil, ErrUnknownFormat
+		}
+	}
+
+	switch format {
+	case FormatDnsmasq:
+		return ParseDnsmasq(br)
+	case FormatISC:
+		return ParseISC(br)
+	case FormatKea:
+		return ParseKea(br)
+	}
+
+	return nil, fmt.Errorf("leases: unsupported format %s", format)
+}
+
+func detect(head []byte) Format {
+	sc := bufio.NewScanner(bytes.NewReader(head))
+
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "address,"):
+			return FormatKea
+		case strings.HasPrefix(line, "lease ") || strings.HasPrefix(line, "server-duid ") ||
+			strings.HasPrefix(line, "authoring-byte-order ") || strings.HasPrefix(line, "failover "):
+			return FormatISC
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) >= 4 && (isDigits(fields[0]) || fields[0] == "duid") {
+			return FormatDnsmasq
+		}
+
+		return FormatAuto
+	}
+
+	return FormatAuto
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
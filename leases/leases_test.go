@@ -0,0 +1,219 @@
+package leases
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/logocomune/maclookup-go"
+	"github.com/stretchr/testify/assert"
+)
+
+const dnsmasqLeases = `1637229600 00:00:00:11:22:33 192.168.1.10 laptop 01:00:00:00:11:22:33
+0 ac:de:48:00:11:22 192.168.1.11 * *
+duid 00:01:00:01:29:1a:2b:3c:00:00:00:11:22:33
+1637229600 1234 fd00::10 phone 00:01:00:01:29:1a:2b:3c:00:00:00:11:22:33
+`
+
+const iscLeases = `# The format of this file is documented in the dhcpd.leases(5) manual page.
+authoring-byte-order little-endian;
+
+server-duid "\000\001\000\001";
+
+lease 192.168.1.10 {
+  starts 4 2021/11/18 09:00:00;
+  ends 4 2021/11/18 10:00:00;
+  binding state active;
+  next binding state free;
+  hardware ethernet 00:00:00:11:22:33;
+  uid "\001\000\000\000\021\"3";
+  client-hostname "old-name";
+}
+lease 192.168.1.11 {
+  starts epoch 1637226000; # Thu Nov 18 09:00:00 2021
+  ends never;
+  binding state free;
+  hardware ethernet ac:de:48:00:11:22;
+}
+lease 192.168.1.10 {
+  starts 4 2021/11/18 10:00:00;
+  ends 4 2021/11/18 11:00:00;
+  binding state active;
+  hardware ethernet 00:00:00:11:22:33;
+  client-hostname "laptop # 1";
+}
+`
+
+const keaLeases = `address,hwaddr,client_id,valid_lifetime,expire,subnet_id,fqdn_fwd,fqdn_rev,hostname,state,user_context,pool_id
+192.168.1.10,00:00:00:11:22:33,,3600,1637229600,1,0,0,laptop&#x2c office,0,,0
+192.168.1.11,ac:de:48:00:11:22,,3600,1637229600,1,0,0,,0,,0
+192.168.1.11,ac:de:48:00:11:22,,3600,1637233200,1,0,0,,2,,0
+192.168.1.12,,,3600,1637229600,1,0,0,,1,,0
+`
+
+func TestParseDnsmasq(t *testing.T) {
+	leases, err := ParseDnsmasq(strings.NewReader(dnsmasqLeases))
+
+	assert.Nil(t, err)
+	assert.Len(t, leases, 2)
+	assert.Equal(t, "192.168.1.10", leases[0].IP.String())
+	assert.Equal(t, "00:00:00:11:22:33", leases[0].MAC.String())
+	assert.Equal(t, "laptop", leases[0].Hostname)
+	assert.Equal(t, time.Unix(1637229600, 0), leases[0].Expires)
+	assert.True(t, leases[0].Active)
+	assert.Equal(t, "", leases[1].Hostname)
+	assert.True(t, leases[1].Expires.IsZero())
+}
+
+func TestParseDnsmasq_Invalid(t *testing.T) {
+	_, err := ParseDnsmasq(strings.NewReader("1637229600 00:00:00:11:22:33\n"))
+	assert.NotNil(t, err)
+}
+
+func TestParseISC(t *testing.T) {
+	leases, err := ParseISC(strings.NewReader(iscLeases))
+
+	assert.Nil(t, err)
+	assert.Len(t, leases, 2)
+	assert.Equal(t, "192.168.1.10", leases[0].IP.String())
+	assert.Equal(t, "laptop # 1", leases[0].Hostname)
+	assert.Equal(t, time.Date(2021, 11, 18, 11, 0, 0, 0, time.UTC), leases[0].Expires)
+	assert.True(t, leases[0].Active)
+	assert.Equal(t, "ac:de:48:00:11:22", leases[1].MAC.String())
+	assert.False(t, leases[1].Active)
+	assert.True(t, leases[1].Expires.IsZero())
+}
+
+func TestParseISC_Unterminated(t *testing.T) {
+	_, err := ParseISC(strings.NewReader("lease 10.0.0.1 {\n hardware ethernet 00:00:00:11:22:33;\n"))
+	assert.NotNil(t, err)
+}
+
+func TestParseKea(t *testing.T) {
+	leases, err := ParseKea(strings.NewReader(keaLeases))
+
+	assert.Nil(t, err)
+	assert.Len(t, leases, 2)
+	assert.Equal(t, "laptop, office", leases[0].Hostname)
+	assert.True(t, leases[0].Active)
+	assert.Equal(t, time.Unix(1637229600, 0), leases[0].Expires)
+	assert.Equal(t, "192.168.1.11", leases[1].IP.String())
+	assert.False(t, leases[1].Active)
+	assert.Equal(t, time.Unix(1637233200, 0), leases[1].Expires)
+}
+
+func TestParseKea_MissingColumn(t *testing.T) {
+	_, err := ParseKea(strings.NewReader("address,client_id\n"))
+	assert.NotNil(t, err)
+}
+
+func TestParse_Detect(t *testing.T) {
+	for input, want := range map[string]int{dnsmasqLeases: 2, iscLeases: 2, keaLeases: 2} {
+		leases, err := Parse(FormatAuto, strings.NewReader(input))
+		assert.Nil(t, err)
+		assert.Len(t, leases, want)
+	}
+
+	_, err := Parse(FormatAuto, strings.NewReader("hello world\n"))
+	assert.True(t, errors.Is(err, ErrUnknownFormat))
+}
+
+func TestParseFormat(t *testing.T) {
+	f, err := ParseFormat("ISC")
+	assert.Nil(t, err)
+	assert.Equal(t, FormatISC, f)
+	assert.Equal(t, "isc", f.String())
+
+	_, err = ParseFormat("csv")
+	assert.NotNil(t, err)
+}
+
+func TestLease_Expired(t *testing.T) {
+	now := time.Now()
+
+	assert.False(t, Lease{}.Expired(now))
+	assert.True(t, Lease{Expires: now.Add(-time.Second)}.Expired(now))
+	assert.False(t, Lease{Expires: now.Add(time.Second)}.Expired(now))
+}
+
+type fakeResolver struct {
+	calls int
+	err   error
+}
+
+func (f *fakeResolver) CompanyName(mac string) (maclookup.ResponseVendorName, error) {
+	f.calls++
+
+	var r maclookup.ResponseVendorName
+	r.Found, r.Company = true, "COMPANY "+maclookup.Prefix(mac)[:6]
+
+	return r, f.err
+}
+
+func (f *fakeResolver) Lookup(mac string) (maclookup.ResponseMACInfo, error) {
+	f.calls++
+
+	var r maclookup.ResponseMACInfo
+	r.Found, r.Company, r.Country = true, "COMPANY "+maclookup.Prefix(mac)[:6], "US"
+
+	return r, f.err
+}
+
+func TestEnrich(t *testing.T) {
+	leases, err := ParseDnsmasq(strings.NewReader(dnsmasqLeases + "0 00:00:00:11:22:34 192.168.1.12 * *\n"))
+	assert.Nil(t, err)
+
+	r := &fakeResolver{}
+	entries, err := Enrich(leases, r)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, r.calls)
+	assert.Len(t, entries, 3)
+	assert.Equal(t, "COMPANY 000000", entries[0].Company)
+	assert.Equal(t, "laptop", entries[0].Hostname)
+	assert.Equal(t, "COMPANY ACDE48", entries[1].Company)
+
+	info, err := EnrichInfo(leases, &fakeResolver{})
+	assert.Nil(t, err)
+	assert.Equal(t, "US", info[2].Country)
+}
+
+func TestEnrich_Error(t *testing.T) {
+	leases, err := ParseDnsmasq(strings.NewReader(dnsmasqLeases))
+	assert.Nil(t, err)
+
+	entries, err := Enrich(leases, &fakeResolver{err: &maclookup.BadAPIKey{Err: errors.New("bad api key")}})
+
+	var e *maclookup.BadAPIKey
+
+	assert.True(t, errors.As(err, &e))
+	assert.Empty(t, entries)
+}
+
+func TestEntry_JSON(t *testing.T) {
+	mac, _ := net.ParseMAC("00:00:00:11:22:33")
+	entry := InfoEntry{
+		Lease:   Lease{IP: net.ParseIP("192.168.1.10"), MAC: mac, Hostname: "printer", Active: true},
+		MACInfo: maclookup.MACInfo{Found: true, MacPrefix: "000000", Company: "XEROX CORPORATION"},
+	}
+
+	data, err := json.Marshal(entry)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"Hostname":"printer"`)
+	assert.Contains(t, string(data), `"MAC":"00:00:00:11:22:33"`)
+	assert.Contains(t, string(data), `"company":"XEROX CORPORATION"`)
+
+	var decoded InfoEntry
+
+	assert.Nil(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, entry.Lease, decoded.Lease)
+	assert.Equal(t, entry.MACInfo, decoded.MACInfo)
+
+	data, err = json.Marshal(Entry{Lease: entry.Lease, CompanyInfo: maclookup.CompanyInfo{Found: true, Company: "XEROX CORPORATION"}})
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"Hostname":"printer"`)
+	assert.Contains(t, string(data), `"company":"XEROX CORPORATION"`)
+}
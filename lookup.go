@@ -13,28 +13,34 @@ import (
 
 //Lookup retrieve MAC information from API.
 func (c Client) Lookup(mac string) (ResponseMACInfo, error) {
-	url := c.prefixURI + apiMAC + cleanMac(mac)
-	if c.apiKey != "" {
-		url += apiKeyParam + c.apiKey
+	cl, err := c.begin(EndpointLookup, mac)
+	if err != nil {
+		return ResponseMACInfo{}, err
 	}
 
-	return c.getMacInfo(url)
+	result, a, err := c.send(cl, func(ctx context.Context, prefixURI string, a *attempt) (interface{}, error) {
+		return c.getMacInfo(ctx, c.endpointURL(prefixURI, cl.event.Prefix, ""), a)
+	})
+	response, _ := result.(ResponseMACInfo)
+	response.Annotations = c.annotate(mac, response.MACInfo)
+
+	err = c.end(cl, a, response.Found, response.IsPrivate, response.RespTime, response.RateLimit, err)
+
+	return response, err
 }
 
-func (c Client) getMacInfo(url string) (ResponseMACInfo, error) {
+func (c Client) getMacInfo(ctx context.Context, url string, a *attempt) (ResponseMACInfo, error) {
 	var response ResponseMACInfo
 
 	start := time.Now()
-	timeout, cancel := context.WithTimeout(context.Background(), c.timeOut)
+	timeout, cancel := context.WithTimeout(ctx, c.timeOut)
 	defer cancel()
 
-	req, err := http.NewRequestWithContext(timeout, "GET", url, nil)
+	req, err := c.newRequest(timeout, url)
 	if err != nil {
 		return response, &HTTPClientError{Err: err}
 	}
 
-	req.Header.Set("User-Agent", ua)
-	req.Header.Set("Accept", "*")
 	resp, err := c.client.Do(req)
 
 	if err != nil {
@@ -48,7 +54,9 @@ func (c Client) getMacInfo(url string) (ResponseMACInfo, error) {
 		Reset:     parseTimeHeader(resp.Header, xRateReset),
 	}
 
-	if err := checkStatusMacInfo(resp.StatusCode, resp.Body, response.Limit, response.Reset); err != nil {
+	a.responseReceived(resp.StatusCode, response.RateLimit)
+
+	if err := checkStatusMacInfo(resp.Body, newErrorResponse(resp, response.RateLimit)); err != nil {
 		return response, err
 	}
 
@@ -59,7 +67,11 @@ func (c Client) getMacInfo(url string) (ResponseMACInfo, error) {
 	response.RespTime = time.Since(start)
 
 	if err != nil || !apiRespose.Success {
-		return response, &BadAPIResponse{Err: err}
+		if err == nil {
+			err = errors.New("unsuccessful api response")
+		}
+
+		return response, &BadAPIResponse{Err: err, ErrorResponse: newErrorResponse(resp, response.RateLimit)}
 	}
 
 	//Decoupling api response
@@ -76,41 +88,56 @@ func (c Client) getMacInfo(url string) (ResponseMACInfo, error) {
 	response.IsRand = apiRespose.IsRand
 	response.IsPrivate = apiRespose.IsPrivate
 
+	if _, err := response.MACInfo.Typed(); err != nil {
+		return response, &BadAPIResponse{Err: err, ErrorResponse: newErrorResponse(resp, response.RateLimit)}
+	}
+
 	return response, nil
 }
 
-func checkStatusMacInfo(statusCode int, body io.Reader, rateLimit int64, rateLimitReset time.Time) error {
-	switch statusCode {
+func checkStatusMacInfo(body io.Reader, er ErrorResponse) error {
+	if er.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var e errorResponseAPIV2
+	decoded := json.NewDecoder(body).Decode(&e) == nil
+
+	if decoded {
+		er.ErrorCode = e.ErrorCode
+		er.MoreInfo = e.MoreInfo
+		er.Message = e.Error
+	}
+
+	switch er.StatusCode {
 	case http.StatusBadRequest:
-		var e errorResponseAPIV2
-		err := json.NewDecoder(body).Decode(&e)
 		msg := "client request error"
 
-		if err == nil {
+		if decoded {
 			msg = e.Error
 		}
 
-		return &BadAPIRequest{Err: errors.New(strings.ToLower(msg))}
+		return &BadAPIRequest{Err: errors.New(strings.ToLower(msg)), ErrorResponse: er}
 
 	case http.StatusUnauthorized:
-		var e errorResponseAPIV2
-		err := json.NewDecoder(body).Decode(&e)
 		msg := "bad api key"
 
-		if err == nil {
+		if decoded {
 			msg = e.Error
 		}
 
-		return &BadAPIKey{Err: errors.New(msg)}
+		return &BadAPIKey{Err: errors.New(msg), ErrorResponse: er}
 	case http.StatusTooManyRequests:
 		return &RateLimitsExceeded{
-			Limit: rateLimit,
-			Reset: rateLimitReset,
+			Limit:         er.RateLimit.Limit,
+			Reset:         er.RateLimit.Reset,
+			Err:           ErrRateLimited,
+			ErrorResponse: er,
 		}
 
-	case http.StatusOK:
-		return nil
+	case http.StatusNotFound:
+		return &HTTPClientError{Err: ErrNotFound, ErrorResponse: er}
 	}
 
-	return &HTTPClientError{Err: errors.New("unexpected http status: " + strconv.Itoa(statusCode))}
+	return &HTTPClientError{Err: errors.New("unexpected http status: " + strconv.Itoa(er.StatusCode)), ErrorResponse: er}
 }
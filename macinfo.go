@@ -0,0 +1,236 @@
+package maclookup
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/logocomune/maclookup-go/internal/macaddr"
+)
+
+//UpdatedFormat is the layout of MACInfo.Updated.
+const UpdatedFormat = "2006-01-02"
+
+//BlockType is the IEEE registry of an assignment.
+type BlockType string
+
+//IEEE registries.
+const (
+	BlockTypeMAL BlockType = "MA-L"
+	BlockTypeMAM BlockType = "MA-M"
+	BlockTypeMAS BlockType = "MA-S"
+	BlockTypeIAB BlockType = "IAB"
+	BlockTypeCID BlockType = "CID"
+)
+
+// blockPrefixLen is the prefix length, in bits, of the assignments of each registry.
+var blockPrefixLen = map[BlockType]int{
+	BlockTypeMAL: 24,
+	BlockTypeMAM: 28,
+	BlockTypeMAS: 36,
+	BlockTypeIAB: 36,
+	BlockTypeCID: 24,
+}
+
+//ParseBlockType parses a block type as returned by the API, case insensitively.
+func ParseBlockType(s string) (BlockType, error) {
+	t := BlockType(strings.ToUpper(strings.TrimSpace(s)))
+	if _, ok := blockPrefixLen[t]; !ok {
+		return "", fmt.Errorf("invalid block type %q", s)
+	}
+
+	return t, nil
+}
+
+//PrefixLen returns the prefix length in bits of the assignments of t, 0 for an unknown type.
+func (t BlockType) PrefixLen() int {
+	return blockPrefixLen[t]
+}
+
+func (t BlockType) String() string {
+	return string(t)
+}
+
+//Country is an ISO 3166-1 alpha-2 country code.
+type Country string
+
+//ParseCountry parses a two letter country code, case insensitively.
+func ParseCountry(s string) (Country, error) {
+	c := strings.ToUpper(strings.TrimSpace(s))
+	if len(c) != 2 || c[0] < 'A' || c[0] > 'Z' || c[1] < 'A' || c[1] > 'Z' {
+		return "", fmt.Errorf("invalid ISO 3166 country code %q", s)
+	}
+
+	return Country(c), nil
+}
+
+func (c Country) String() string {
+	return string(c)
+}
+
+//TypedMACInfo is MACInfo with parsed fields.
+type TypedMACInfo struct {
+	Found     bool
+	Company   string
+	Address   string
+	Country   Country
+	BlockType BlockType
+	//BlockStart and BlockEnd are the first and the last address of the block.
+	BlockStart net.HardwareAddr
+	BlockEnd   net.HardwareAddr
+	//PrefixLen is the number of bits shared by every address of the block.
+	PrefixLen int
+	BlockSize int
+	Updated   time.Time
+	IsRand    bool
+	IsPrivate bool
+}
+
+//Typed parses the fields of m. Empty fields are left to their zero value.
+func (m MACInfo) Typed() (TypedMACInfo, error) {
+	t := TypedMACInfo{
+		Found:     m.Found,
+		Company:   m.Company,
+		Address:   m.Address,
+		BlockSize: m.BlockSize,
+		IsRand:    m.IsRand,
+		IsPrivate: m.IsPrivate,
+	}
+
+	var err error
+
+	if t.BlockType, err = m.Type(); err != nil {
+		return t, err
+	}
+
+	if t.Country, err = m.CountryCode(); err != nil {
+		return t, err
+	}
+
+	if t.Updated, err = m.UpdatedAt(); err != nil {
+		return t, err
+	}
+
+	if t.BlockStart, t.BlockEnd, t.PrefixLen, err = m.Bounds(); err != nil {
+		return t, err
+	}
+
+	return t, nil
+}
+
+//Type returns the parsed BlockType, or "" when it is empty.
+func (m MACInfo) Type() (BlockType, error) {
+	if m.BlockType == "" {
+		return "", nil
+	}
+
+	return ParseBlockType(m.BlockType)
+}
+
+//CountryCode returns the parsed Country, or "" when it is empty.
+func (m MACInfo) CountryCode() (Country, error) {
+	if m.Country == "" {
+		return "", nil
+	}
+
+	return ParseCountry(m.Country)
+}
+
+//UpdatedAt returns the parsed Updated date (UTC), or the zero time when it is empty.
+func (m MACInfo) UpdatedAt() (time.Time, error) {
+	if m.Updated == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(UpdatedFormat, m.Updated)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid updated date %q", m.Updated)
+	}
+
+	return t, nil
+}
+
+//Bounds returns the first and the last address of the block and its prefix length in bits,
+//or nil bounds when BlockStart and BlockEnd are empty.
+func (m MACInfo) Bounds() (start, end net.HardwareAddr, prefixLen int, err error) {
+	if m.BlockStart == "" && m.BlockEnd == "" {
+		return nil, nil, 0, nil
+	}
+
+	if start, err = parseHexMAC(m.BlockStart); err != nil {
+		return nil, nil, 0, fmt.Errorf("invalid block start: %w", err)
+	}
+
+	if end, err = parseHexMAC(m.BlockEnd); err != nil {
+		return nil, nil, 0, fmt.Errorf("invalid block end: %w", err)
+	}
+
+	prefixLen = commonPrefixLen(start, end)
+	if !isBlock(start, end, prefixLen) {
+		return nil, nil, 0, fmt.Errorf("invalid block %s-%s", m.BlockStart, m.BlockEnd)
+	}
+
+	return start, end, prefixLen, nil
+}
+
+//Contains reports whether the block of m contains mac, a full address with or without separators.
+func (m MACInfo) Contains(mac string) bool {
+	start, end, _, err := m.Bounds()
+	if err != nil || start == nil {
+		return false
+	}
+
+	addr, err := parseHexMAC(mac)
+	if err != nil {
+		return false
+	}
+
+	return bytes.Compare(addr, start) >= 0 && bytes.Compare(addr, end) <= 0
+}
+
+// parseHexMAC parses a 48 bit address with or without separators.
+func parseHexMAC(s string) (net.HardwareAddr, error) {
+	h := macaddr.Clean(s)
+	if len(h) != 12 {
+		return nil, fmt.Errorf("invalid MAC address %q", s)
+	}
+
+	b, err := hex.DecodeString(h)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MAC address %q", s)
+	}
+
+	return net.HardwareAddr(b), nil
+}
+
+func commonPrefixLen(a, b net.HardwareAddr) int {
+	for i := range a {
+		if x := a[i] ^ b[i]; x != 0 {
+			n := i * 8
+
+			for x&0x80 == 0 {
+				x <<= 1
+				n++
+			}
+
+			return n
+		}
+	}
+
+	return len(a) * 8
+}
+
+// isBlock reports whether start and end are the first and the last address of a prefixLen block.
+func isBlock(start, end net.HardwareAddr, prefixLen int) bool {
+	for bit := prefixLen; bit < len(start)*8; bit++ {
+		mask := byte(0x80 >> uint(bit%8))
+		if start[bit/8]&mask != 0 || end[bit/8]&mask == 0 {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,79 @@
+package maclookup
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMACInfo_Typed(t *testing.T) {
+	info := MACInfo{
+		Found:      true,
+		MacPrefix:  "70B3D5F2F",
+		Company:    "ACME",
+		Country:    "it",
+		BlockStart: "70B3D5F2F000",
+		BlockEnd:   "70B3D5F2FFFF",
+		BlockSize:  4095,
+		BlockType:  "ma-s",
+		Updated:    "2019-07-02",
+	}
+
+	typed, err := info.Typed()
+	assert.Nil(t, err)
+	assert.Equal(t, BlockTypeMAS, typed.BlockType)
+	assert.Equal(t, 36, typed.BlockType.PrefixLen())
+	assert.Equal(t, Country("IT"), typed.Country)
+	assert.Equal(t, time.Date(2019, 7, 2, 0, 0, 0, 0, time.UTC), typed.Updated)
+	assert.Equal(t, net.HardwareAddr{0x70, 0xb3, 0xd5, 0xf2, 0xf0, 0x00}, typed.BlockStart)
+	assert.Equal(t, net.HardwareAddr{0x70, 0xb3, 0xd5, 0xf2, 0xff, 0xff}, typed.BlockEnd)
+	assert.Equal(t, 36, typed.PrefixLen)
+
+	assert.True(t, info.Contains("70:b3:d5:f2:f1:23"))
+	assert.True(t, info.Contains("70B3D5F2FFFF"))
+	assert.False(t, info.Contains("70:b3:d5:f2:e1:23"))
+	assert.False(t, info.Contains("70:b3:d5"))
+
+	typed, err = MACInfo{}.Typed()
+	assert.Nil(t, err)
+	assert.Nil(t, typed.BlockStart)
+	assert.False(t, MACInfo{}.Contains("000000000000"))
+}
+
+func TestMACInfo_TypedErrors(t *testing.T) {
+	for _, info := range []MACInfo{
+		{BlockType: "MA-X"},
+		{Country: "ITA"},
+		{Updated: "17/11/2015"},
+		{BlockStart: "000000000000"},
+		{BlockStart: "000000000000", BlockEnd: "000000FFFFF"},
+		{BlockStart: "000000000001", BlockEnd: "000000FFFFFF"},
+	} {
+		_, err := info.Typed()
+		assert.NotNil(t, err, "%+v", info)
+	}
+}
+
+func TestClient_LookupInvalidFields(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"success":true,"found":true,"macPrefix":"000000","company":"XEROX CORPORATION","blockStart":"000000000000","blockEnd":"000000FFFFFF","blockType":"MA-L","updated":"yesterday"}`)
+	}))
+	defer ts.Close()
+
+	client := New()
+	client.WithPrefixURI(ts.URL)
+
+	resp, err := client.Lookup("000000")
+
+	var e *BadAPIResponse
+
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, http.StatusOK, e.StatusCode)
+	assert.Equal(t, "yesterday", resp.Updated)
+}
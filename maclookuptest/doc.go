@@ -0,0 +1,19 @@
+// Package maclookuptest provides a fake MACLookup API v2 server and an in-memory
+// maclookup.Resolver for tests.
+//
+// The server is seeded with vendor data, can require API keys, emits X-RateLimit-* headers
+// and 429s after a configurable number of requests, and can inject latency, 5xx errors and malformed bodies:
+//
+//	s := maclookuptest.NewServer()
+//	defer s.Close()
+//	s.WithRateLimit(2, time.Second)
+//
+//	client := maclookup.New()
+//	client.WithPrefixURI(s.URL)
+//
+// Code that depends on maclookup.Resolver (or MACInfoResolver/CompanyNameResolver)
+// can use a Fake instead, without any HTTP server:
+//
+//	f := maclookuptest.NewFake()
+//	f.FailNext(1, &maclookup.RateLimitsExceeded{Limit: 2})
+package maclookuptest
@@ -0,0 +1,210 @@
+package maclookuptest
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/logocomune/maclookup-go"
+	"github.com/logocomune/maclookup-go/internal/apiv2"
+)
+
+//Fake is an in-memory maclookup.Resolver for tests.
+//It answers from a vendor table seeded with DefaultVendors, records every call
+//and can be programmed to return any error, including the maclookup error types.
+type Fake struct {
+	mu        sync.Mutex
+	vendors   vendorTable
+	rateLimit maclookup.RateLimit
+	errNext   []error
+	errMAC    map[string]error
+	err       error
+	calls     []Call
+}
+
+//Call is a call received by a Fake.
+type Call struct {
+	Method string
+	MAC    string
+	Err    error
+}
+
+//Methods recorded in Call.
+const (
+	MethodLookup      = "Lookup"
+	MethodCompanyName = "CompanyName"
+)
+
+var _ maclookup.Resolver = (*Fake)(nil)
+
+//NewFake creates a Fake seeded with DefaultVendors.
+func NewFake() *Fake {
+	f := &Fake{vendors: make(vendorTable), errMAC: make(map[string]error)}
+	f.vendors.add(DefaultVendors())
+
+	return f
+}
+
+//AddVendors adds or replaces vendor blocks, keyed by MacPrefix (6, 7 or 9 hex digits).
+func (f *Fake) AddVendors(vendors ...maclookup.MACInfo) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.vendors.add(vendors)
+}
+
+//ClearVendors removes every vendor block, including the default ones.
+func (f *Fake) ClearVendors() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.vendors = make(vendorTable)
+}
+
+//WithRateLimit sets the RateLimit returned with every response.
+func (f *Fake) WithRateLimit(rl maclookup.RateLimit) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.rateLimit = rl
+}
+
+//Fail makes every call return err. Fail(nil) restores normal answers.
+func (f *Fake) Fail(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.err = err
+}
+
+//FailNext makes the next n calls return err.
+func (f *Fake) FailNext(n int, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i := 0; i < n; i++ {
+		f.errNext = append(f.errNext, err)
+	}
+}
+
+//FailMAC makes every call for mac return err. FailMAC(mac, nil) removes the error.
+func (f *Fake) FailMAC(mac string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err == nil {
+		delete(f.errMAC, apiv2.CleanMAC(mac))
+		return
+	}
+
+	f.errMAC[apiv2.CleanMAC(mac)] = err
+}
+
+//Calls returns the calls received so far.
+func (f *Fake) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]Call(nil), f.calls...)
+}
+
+//Reset forgets the recorded calls and the programmed errors.
+func (f *Fake) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls = nil
+	f.errNext = nil
+	f.errMAC = make(map[string]error)
+	f.err = nil
+}
+
+//Lookup implements maclookup.MACInfoResolver.
+func (f *Fake) Lookup(mac string) (maclookup.ResponseMACInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	response := maclookup.ResponseMACInfo{RateLimit: f.rateLimit}
+
+	info, err := f.resolve(MethodLookup, mac)
+	if err != nil {
+		return response, err
+	}
+
+	response.MACInfo = info
+
+	return response, nil
+}
+
+//CompanyName implements maclookup.CompanyNameResolver.
+func (f *Fake) CompanyName(mac string) (maclookup.ResponseVendorName, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	response := maclookup.ResponseVendorName{RateLimit: f.rateLimit}
+
+	info, err := f.resolve(MethodCompanyName, mac)
+	if err != nil {
+		return response, err
+	}
+
+	response.Found = info.Found
+	response.IsPrivate = info.IsPrivate
+
+	if info.Found && !info.IsPrivate {
+		response.Company = info.Company
+	}
+
+	return response, nil
+}
+
+func (f *Fake) resolve(method, mac string) (maclookup.MACInfo, error) {
+	clean := apiv2.CleanMAC(mac)
+	err := f.programmedError(clean)
+
+	if err == nil {
+		if e := apiv2.ValidateMAC(clean); e != nil {
+			err = &maclookup.BadAPIRequest{
+				Err: errors.New(strings.ToLower(e.Error)),
+				ErrorResponse: maclookup.ErrorResponse{
+					StatusCode: http.StatusBadRequest,
+					ErrorCode:  e.ErrorCode,
+					MoreInfo:   e.MoreInfo,
+					Message:    e.Error,
+					RateLimit:  f.rateLimit,
+				},
+			}
+		}
+	}
+
+	f.calls = append(f.calls, Call{Method: method, MAC: mac, Err: err})
+
+	if err != nil {
+		return maclookup.MACInfo{}, err
+	}
+
+	info, found := f.vendors.lookup(clean)
+	if !found {
+		return maclookup.MACInfo{IsRand: apiv2.IsRand(clean)}, nil
+	}
+
+	info.IsRand = apiv2.IsRand(clean)
+
+	return info, nil
+}
+
+func (f *Fake) programmedError(mac string) error {
+	if len(f.errNext) > 0 {
+		err := f.errNext[0]
+		f.errNext = f.errNext[1:]
+
+		return err
+	}
+
+	if err, ok := f.errMAC[mac]; ok {
+		return err
+	}
+
+	return f.err
+}
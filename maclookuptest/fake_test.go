@@ -0,0 +1,94 @@
+package maclookuptest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/logocomune/maclookup-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFake_Lookup(t *testing.T) {
+	var r maclookup.Resolver = NewFake()
+
+	info, err := r.Lookup("00:00:00:11:22:33")
+	assert.Nil(t, err)
+	assert.True(t, info.Found)
+	assert.Equal(t, "XEROX CORPORATION", info.Company)
+
+	info, err = r.Lookup("02:42:ac:11:00:02")
+	assert.Nil(t, err)
+	assert.False(t, info.Found)
+	assert.True(t, info.IsRand)
+
+	_, err = r.Lookup("0000")
+
+	var e *maclookup.BadAPIRequest
+
+	assert.True(t, errors.As(err, &e))
+}
+
+func TestFake_CompanyName(t *testing.T) {
+	f := NewFake()
+	f.AddVendors(maclookup.MACInfo{MacPrefix: "70:B3:D5:F2:F", Company: "MA-S VENDOR"})
+
+	name, err := f.CompanyName("70B3D5F2F001")
+	assert.Nil(t, err)
+	assert.Equal(t, "MA-S VENDOR", name.Company)
+
+	name, err = f.CompanyName("ACDE48")
+	assert.Nil(t, err)
+	assert.True(t, name.IsPrivate)
+	assert.Empty(t, name.Company)
+
+	f.ClearVendors()
+
+	name, err = f.CompanyName("000000")
+	assert.Nil(t, err)
+	assert.False(t, name.Found)
+}
+
+func TestFake_Errors(t *testing.T) {
+	f := NewFake()
+	reset := time.Unix(1637229600, 0)
+	f.WithRateLimit(maclookup.RateLimit{Limit: 2, Remaining: 0, Reset: reset})
+
+	f.FailNext(1, &maclookup.RateLimitsExceeded{Limit: 2, Reset: reset})
+	f.FailMAC("ac:de:48", &maclookup.HTTPClientError{Err: errors.New("endpoint not found")})
+
+	r, err := f.Lookup("000000")
+
+	var rateErr *maclookup.RateLimitsExceeded
+
+	assert.True(t, errors.As(err, &rateErr))
+	assert.Equal(t, int64(2), r.Limit)
+
+	_, err = f.Lookup("000000")
+	assert.Nil(t, err)
+
+	_, err = f.CompanyName("ACDE48")
+
+	var httpErr *maclookup.HTTPClientError
+
+	assert.True(t, errors.As(err, &httpErr))
+
+	f.Fail(&maclookup.BadAPIKey{Err: errors.New("bad api key")})
+
+	_, err = f.CompanyName("000000")
+
+	var keyErr *maclookup.BadAPIKey
+
+	assert.True(t, errors.As(err, &keyErr))
+
+	calls := f.Calls()
+	assert.Len(t, calls, 4)
+	assert.Equal(t, Call{Method: MethodLookup, MAC: "000000", Err: &maclookup.RateLimitsExceeded{Limit: 2, Reset: reset}}, calls[0])
+	assert.Equal(t, MethodCompanyName, calls[3].Method)
+
+	f.Reset()
+
+	_, err = f.CompanyName("ACDE48")
+	assert.Nil(t, err)
+	assert.Len(t, f.Calls(), 1)
+}
@@ -0,0 +1,177 @@
+package maclookuptest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/logocomune/maclookup-go"
+	"github.com/logocomune/maclookup-go/internal/apiv2"
+)
+
+//Server is a fake MACLookup API v2 server.
+//Its URL can be passed to maclookup.Client.WithPrefixURI.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	vendors  vendorTable
+	apiKeys  map[string]bool
+	limiter  *apiv2.Limiter
+	latency  time.Duration
+	faults   []fault
+	requests int
+}
+
+type fault struct {
+	status    int
+	malformed bool
+}
+
+//NewServer starts a fake server seeded with DefaultVendors.
+//API keys and rate limits are disabled until configured.
+func NewServer() *Server {
+	s := &Server{vendors: make(vendorTable)}
+	s.AddVendors(DefaultVendors()...)
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+
+	return s
+}
+
+//NewClient returns a client pointed at the server.
+func (s *Server) NewClient() *maclookup.Client {
+	c := maclookup.New()
+	c.WithPrefixURI(s.URL)
+
+	return c
+}
+
+//AddVendors adds or replaces vendor blocks, keyed by MacPrefix (6, 7 or 9 hex digits).
+func (s *Server) AddVendors(vendors ...maclookup.MACInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.vendors.add(vendors)
+}
+
+//ClearVendors removes every vendor block, including the default ones.
+func (s *Server) ClearVendors() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.vendors = make(vendorTable)
+}
+
+//WithAPIKeys makes the server require one of keys on every request.
+//Requests without a valid key are answered with 401.
+func (s *Server) WithAPIKeys(keys ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.apiKeys = make(map[string]bool, len(keys))
+	for _, k := range keys {
+		s.apiKeys[k] = true
+	}
+}
+
+//WithRateLimit allows limit requests per API key every window, answering 429 afterwards.
+//Every response then carries the X-RateLimit-* headers.
+func (s *Server) WithRateLimit(limit int64, window time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.limiter = apiv2.NewLimiter(limit, window)
+}
+
+//WithLatency delays every response by d.
+func (s *Server) WithLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.latency = d
+}
+
+//FailNext answers the next n requests with the given HTTP status and a "SYSTEM ERROR" body.
+func (s *Server) FailNext(n int, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := 0; i < n; i++ {
+		s.faults = append(s.faults, fault{status: status})
+	}
+}
+
+//MalformNext answers the next n requests with 200 and a truncated JSON body.
+func (s *Server) MalformNext(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := 0; i < n; i++ {
+		s.faults = append(s.faults, fault{status: http.StatusOK, malformed: true})
+	}
+}
+
+//Requests returns the number of requests received so far.
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests++
+	latency := s.latency
+	s.mu.Unlock()
+
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	req, ok := apiv2.ParseRequest(r)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.apiKeys) > 0 && !s.apiKeys[req.APIKey] {
+		apiv2.WriteError(w, req.CompanyName, http.StatusUnauthorized, apiv2.Unauthorized())
+		return
+	}
+
+	if s.limiter != nil && !s.limiter.Allow(req.APIKey, w.Header()) {
+		apiv2.WriteError(w, req.CompanyName, http.StatusTooManyRequests, apiv2.TooManyRequests())
+		return
+	}
+
+	if len(s.faults) > 0 {
+		f := s.faults[0]
+		s.faults = s.faults[1:]
+
+		if f.malformed {
+			apiv2.WriteText(w, f.status, `{"success":true,"found":tr`)
+		} else {
+			apiv2.WriteText(w, f.status, "SYSTEM ERROR")
+		}
+
+		return
+	}
+
+	mac := apiv2.CleanMAC(req.MAC)
+	if e := apiv2.ValidateMAC(mac); e != nil {
+		apiv2.WriteError(w, req.CompanyName, http.StatusBadRequest, *e)
+		return
+	}
+
+	info, _ := s.vendors.lookup(mac)
+	apiv2.WriteMACInfo(w, req.CompanyName, mac, info)
+}
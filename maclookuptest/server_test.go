@@ -0,0 +1,162 @@
+package maclookuptest
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/logocomune/maclookup-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_Lookup(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	client := s.NewClient()
+
+	r, err := client.Lookup("00:00:00:11:22:33")
+	assert.Nil(t, err)
+	assert.True(t, r.Found)
+	assert.Equal(t, "XEROX CORPORATION", r.Company)
+	assert.Equal(t, "MA-L", r.BlockType)
+
+	r, err = client.Lookup("0A:00:00")
+	assert.Nil(t, err)
+	assert.False(t, r.Found)
+	assert.True(t, r.IsRand)
+
+	_, err = client.Lookup("0000")
+
+	var e *maclookup.BadAPIRequest
+
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, 3, s.Requests())
+}
+
+func TestServer_CompanyName(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	s.AddVendors(maclookup.MACInfo{MacPrefix: "70B3D5F2F", Company: "MA-S VENDOR", BlockType: "MA-S"})
+
+	client := s.NewClient()
+
+	r, err := client.CompanyName("000000")
+	assert.Nil(t, err)
+	assert.Equal(t, "XEROX CORPORATION", r.Company)
+
+	r, err = client.CompanyName("ACDE48")
+	assert.Nil(t, err)
+	assert.True(t, r.Found)
+	assert.True(t, r.IsPrivate)
+
+	r, err = client.CompanyName("70:B3:D5:F2:F0:01")
+	assert.Nil(t, err)
+	assert.Equal(t, "MA-S VENDOR", r.Company)
+
+	r, err = client.CompanyName("70B3D5")
+	assert.Nil(t, err)
+	assert.False(t, r.Found)
+
+	s.ClearVendors()
+
+	r, err = client.CompanyName("000000")
+	assert.Nil(t, err)
+	assert.False(t, r.Found)
+}
+
+func TestServer_APIKeys(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	s.WithAPIKeys("GOOD")
+
+	client := s.NewClient()
+
+	var e *maclookup.BadAPIKey
+
+	_, err := client.Lookup("000000")
+	assert.True(t, errors.As(err, &e))
+
+	client.WithAPIKey("BAD")
+	_, err = client.CompanyName("000000")
+	assert.True(t, errors.As(err, &e))
+	assert.Contains(t, err.Error(), "Bad APIKey")
+
+	client.WithAPIKey("GOOD")
+	_, err = client.Lookup("000000")
+	assert.Nil(t, err)
+
+	client.WithAPIKeyHeader(maclookup.APIKeyHeader)
+	_, err = client.CompanyName("000000")
+	assert.Nil(t, err)
+}
+
+func TestServer_RateLimit(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	s.WithRateLimit(2, time.Minute)
+
+	client := s.NewClient()
+
+	r, err := client.Lookup("000000")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), r.Limit)
+	assert.Equal(t, int64(1), r.Remaining)
+	assert.True(t, r.Reset.After(time.Now()))
+
+	_, err = client.CompanyName("000000")
+	assert.Nil(t, err)
+
+	r, err = client.Lookup("000000")
+
+	var e *maclookup.RateLimitsExceeded
+
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, int64(0), r.Remaining)
+	assert.Equal(t, int64(2), e.Limit)
+}
+
+func TestServer_Faults(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	s.FailNext(1, http.StatusServiceUnavailable)
+	s.MalformNext(1)
+
+	client := s.NewClient()
+
+	_, err := client.Lookup("000000")
+
+	var httpErr *maclookup.HTTPClientError
+
+	assert.True(t, errors.As(err, &httpErr))
+
+	_, err = client.Lookup("000000")
+
+	var respErr *maclookup.BadAPIResponse
+
+	assert.True(t, errors.As(err, &respErr))
+
+	_, err = client.Lookup("000000")
+	assert.Nil(t, err)
+}
+
+func TestServer_Latency(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	s.WithLatency(200 * time.Millisecond)
+
+	client := s.NewClient()
+	client.WithTimeout(10 * time.Millisecond)
+
+	_, err := client.Lookup("000000")
+
+	var e *maclookup.HTTPClientError
+
+	assert.True(t, errors.As(err, &e))
+}
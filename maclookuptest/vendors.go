@@ -0,0 +1,59 @@
+package maclookuptest
+
+import (
+	"github.com/logocomune/maclookup-go"
+	"github.com/logocomune/maclookup-go/internal/apiv2"
+)
+
+// vendorTable maps a cleaned MAC prefix (6, 7 or 9 hex digits) to its block.
+type vendorTable map[string]maclookup.MACInfo
+
+//DefaultVendors is the vendor data a new Server or Fake is seeded with.
+func DefaultVendors() []maclookup.MACInfo {
+	return []maclookup.MACInfo{
+		{
+			Found:      true,
+			MacPrefix:  "000000",
+			Company:    "XEROX CORPORATION",
+			Address:    "M/S 105-50C, WEBSTER NY 14580, US",
+			Country:    "US",
+			BlockStart: "000000000000",
+			BlockEnd:   "000000FFFFFF",
+			BlockSize:  16777215,
+			BlockType:  "MA-L",
+			Updated:    "2015-11-17",
+		},
+		{
+			Found:      true,
+			MacPrefix:  "ACDE48",
+			BlockStart: "ACDE48000000",
+			BlockEnd:   "ACDE48FFFFFF",
+			BlockSize:  16777215,
+			BlockType:  "MA-L",
+			Updated:    "2015-11-17",
+			IsPrivate:  true,
+		},
+	}
+}
+
+func (t vendorTable) add(vendors []maclookup.MACInfo) {
+	for _, v := range vendors {
+		v.Found = true
+		t[apiv2.CleanMAC(v.MacPrefix)] = v
+	}
+}
+
+// lookup returns the longest registered prefix of a cleaned mac.
+func (t vendorTable) lookup(mac string) (maclookup.MACInfo, bool) {
+	for _, n := range []int{9, 7, 6} {
+		if len(mac) < n {
+			continue
+		}
+
+		if info, ok := t[mac[:n]]; ok {
+			return info, true
+		}
+	}
+
+	return maclookup.MACInfo{}, false
+}
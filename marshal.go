@@ -0,0 +1,701 @@
+package maclookup
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+//SchemaVersion is the version of the JSON and binary encodings of the result types.
+//Decoding rejects data written with a newer version. Version 1 has no Annotations.
+const SchemaVersion = 2
+
+//ErrSchemaVersion is returned when decoding data of an unsupported schema version.
+var ErrSchemaVersion = errors.New("unsupported schema version")
+
+// The JSON encodings of MACInfo and CompanyInfo use the field names of the API.
+// MarshalText returns the JSON encoding; MarshalBinary a compact encoding starting with SchemaVersion.
+// Decoded times are in the local time zone, as the times set by the client.
+// The methods of MACInfo, CompanyInfo and RateLimit are promoted to the structs embedding them,
+// which need their own methods to encode their other fields.
+
+type (
+	macInfoJSON     MACInfo
+	companyInfoJSON CompanyInfo
+)
+
+type rateLimitWire struct {
+	Limit     int64  `json:"limit"`
+	Remaining int64  `json:"remaining"`
+	Reset     string `json:"reset,omitempty"`
+}
+
+type responseMACInfoWire struct {
+	SchemaVersion int       `json:"schemaVersion"`
+	RespTime      int64     `json:"respTimeNs"`
+	RateLimit     RateLimit `json:"rateLimit"`
+	MACInfo       MACInfo   `json:"macInfo"`
+	annotationsWire
+}
+
+type responseVendorNameWire struct {
+	SchemaVersion int         `json:"schemaVersion"`
+	RespTime      int64       `json:"respTimeNs"`
+	RateLimit     RateLimit   `json:"rateLimit"`
+	CompanyInfo   CompanyInfo `json:"companyInfo"`
+	annotationsWire
+}
+
+type annotationsWire struct {
+	Vendor         *Vendor         `json:"vendor,omitempty"`
+	Owners         []Vendor        `json:"owners,omitempty"`
+	Classification *Classification `json:"classification,omitempty"`
+	Virtual        *VirtualNIC     `json:"virtual,omitempty"`
+}
+
+//MarshalJSON implements json.Marshaler.
+func (r RateLimit) MarshalJSON() ([]byte, error) {
+	w := rateLimitWire{Limit: r.Limit, Remaining: r.Remaining}
+	if !r.Reset.IsZero() {
+		w.Reset = r.Reset.Format(time.RFC3339Nano)
+	}
+
+	return json.Marshal(w)
+}
+
+//UnmarshalJSON implements json.Unmarshaler.
+func (r *RateLimit) UnmarshalJSON(data []byte) error {
+	var w rateLimitWire
+	if err := json.Unmarshal(data, &w); err != nil {
+		return err
+	}
+
+	*r = RateLimit{Limit: w.Limit, Remaining: w.Remaining}
+
+	if w.Reset != "" {
+		reset, err := time.Parse(time.RFC3339Nano, w.Reset)
+		if err != nil {
+			return err
+		}
+
+		r.Reset = reset.Local()
+	}
+
+	return nil
+}
+
+//MarshalText implements encoding.TextMarshaler.
+func (r RateLimit) MarshalText() ([]byte, error) {
+	return r.MarshalJSON()
+}
+
+//UnmarshalText implements encoding.TextUnmarshaler.
+func (r *RateLimit) UnmarshalText(text []byte) error {
+	return r.UnmarshalJSON(text)
+}
+
+//MarshalBinary implements encoding.BinaryMarshaler.
+func (r RateLimit) MarshalBinary() ([]byte, error) {
+	var e encoder
+
+	e.version()
+	e.rateLimit(r)
+
+	return e.buf, nil
+}
+
+//UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (r *RateLimit) UnmarshalBinary(data []byte) error {
+	d := decoder{buf: data}
+
+	d.readVersion()
+	rl := d.rateLimit()
+
+	if err := d.end(); err != nil {
+		return err
+	}
+
+	*r = rl
+
+	return nil
+}
+
+//MarshalJSON implements json.Marshaler.
+func (m MACInfo) MarshalJSON() ([]byte, error) {
+	return json.Marshal(macInfoJSON(m))
+}
+
+//UnmarshalJSON implements json.Unmarshaler.
+func (m *MACInfo) UnmarshalJSON(data []byte) error {
+	var v macInfoJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	*m = MACInfo(v)
+
+	return nil
+}
+
+//MarshalText implements encoding.TextMarshaler.
+func (m MACInfo) MarshalText() ([]byte, error) {
+	return m.MarshalJSON()
+}
+
+//UnmarshalText implements encoding.TextUnmarshaler.
+func (m *MACInfo) UnmarshalText(text []byte) error {
+	return m.UnmarshalJSON(text)
+}
+
+//MarshalBinary implements encoding.BinaryMarshaler.
+func (m MACInfo) MarshalBinary() ([]byte, error) {
+	var e encoder
+
+	e.version()
+	e.macInfo(m)
+
+	return e.buf, nil
+}
+
+//UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (m *MACInfo) UnmarshalBinary(data []byte) error {
+	d := decoder{buf: data}
+
+	d.readVersion()
+	info := d.macInfo()
+
+	if err := d.end(); err != nil {
+		return err
+	}
+
+	*m = info
+
+	return nil
+}
+
+//MarshalJSON implements json.Marshaler.
+func (c CompanyInfo) MarshalJSON() ([]byte, error) {
+	return json.Marshal(companyInfoJSON(c))
+}
+
+//UnmarshalJSON implements json.Unmarshaler.
+func (c *CompanyInfo) UnmarshalJSON(data []byte) error {
+	var v companyInfoJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	*c = CompanyInfo(v)
+
+	return nil
+}
+
+//MarshalText implements encoding.TextMarshaler.
+func (c CompanyInfo) MarshalText() ([]byte, error) {
+	return c.MarshalJSON()
+}
+
+//UnmarshalText implements encoding.TextUnmarshaler.
+func (c *CompanyInfo) UnmarshalText(text []byte) error {
+	return c.UnmarshalJSON(text)
+}
+
+//MarshalBinary implements encoding.BinaryMarshaler.
+func (c CompanyInfo) MarshalBinary() ([]byte, error) {
+	var e encoder
+
+	e.version()
+	e.companyInfo(c)
+
+	return e.buf, nil
+}
+
+//UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (c *CompanyInfo) UnmarshalBinary(data []byte) error {
+	d := decoder{buf: data}
+
+	d.readVersion()
+	info := d.companyInfo()
+
+	if err := d.end(); err != nil {
+		return err
+	}
+
+	*c = info
+
+	return nil
+}
+
+//MarshalJSON implements json.Marshaler.
+func (r ResponseMACInfo) MarshalJSON() ([]byte, error) {
+	return json.Marshal(responseMACInfoWire{
+		SchemaVersion:   SchemaVersion,
+		RespTime:        int64(r.RespTime),
+		RateLimit:       r.RateLimit,
+		MACInfo:         r.MACInfo,
+		annotationsWire: newAnnotationsWire(r.Annotations),
+	})
+}
+
+//UnmarshalJSON implements json.Unmarshaler.
+func (r *ResponseMACInfo) UnmarshalJSON(data []byte) error {
+	var w responseMACInfoWire
+	if err := json.Unmarshal(data, &w); err != nil {
+		return err
+	}
+
+	if err := checkSchemaVersion(w.SchemaVersion); err != nil {
+		return err
+	}
+
+	*r = ResponseMACInfo{RespTime: time.Duration(w.RespTime), RateLimit: w.RateLimit, MACInfo: w.MACInfo, Annotations: w.annotations()}
+
+	return nil
+}
+
+//MarshalText implements encoding.TextMarshaler.
+func (r ResponseMACInfo) MarshalText() ([]byte, error) {
+	return r.MarshalJSON()
+}
+
+//UnmarshalText implements encoding.TextUnmarshaler.
+func (r *ResponseMACInfo) UnmarshalText(text []byte) error {
+	return r.UnmarshalJSON(text)
+}
+
+//MarshalBinary implements encoding.BinaryMarshaler.
+func (r ResponseMACInfo) MarshalBinary() ([]byte, error) {
+	var e encoder
+
+	e.version()
+	e.varint(int64(r.RespTime))
+	e.rateLimit(r.RateLimit)
+	e.macInfo(r.MACInfo)
+	e.annotations(r.Annotations)
+
+	return e.buf, nil
+}
+
+//UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (r *ResponseMACInfo) UnmarshalBinary(data []byte) error {
+	d := decoder{buf: data}
+
+	d.readVersion()
+	resp := ResponseMACInfo{RespTime: time.Duration(d.varint())}
+	resp.RateLimit = d.rateLimit()
+	resp.MACInfo = d.macInfo()
+	resp.Annotations = d.annotations()
+
+	if err := d.end(); err != nil {
+		return err
+	}
+
+	*r = resp
+
+	return nil
+}
+
+//MarshalJSON implements json.Marshaler.
+func (r ResponseVendorName) MarshalJSON() ([]byte, error) {
+	return json.Marshal(responseVendorNameWire{
+		SchemaVersion:   SchemaVersion,
+		RespTime:        int64(r.RespTime),
+		RateLimit:       r.RateLimit,
+		CompanyInfo:     r.CompanyInfo,
+		annotationsWire: newAnnotationsWire(r.Annotations),
+	})
+}
+
+//UnmarshalJSON implements json.Unmarshaler.
+func (r *ResponseVendorName) UnmarshalJSON(data []byte) error {
+	var w responseVendorNameWire
+	if err := json.Unmarshal(data, &w); err != nil {
+		return err
+	}
+
+	if err := checkSchemaVersion(w.SchemaVersion); err != nil {
+		return err
+	}
+
+	*r = ResponseVendorName{RespTime: time.Duration(w.RespTime), RateLimit: w.RateLimit, CompanyInfo: w.CompanyInfo, Annotations: w.annotations()}
+
+	return nil
+}
+
+//MarshalText implements encoding.TextMarshaler.
+func (r ResponseVendorName) MarshalText() ([]byte, error) {
+	return r.MarshalJSON()
+}
+
+//UnmarshalText implements encoding.TextUnmarshaler.
+func (r *ResponseVendorName) UnmarshalText(text []byte) error {
+	return r.UnmarshalJSON(text)
+}
+
+//MarshalBinary implements encoding.BinaryMarshaler.
+func (r ResponseVendorName) MarshalBinary() ([]byte, error) {
+	var e encoder
+
+	e.version()
+	e.varint(int64(r.RespTime))
+	e.rateLimit(r.RateLimit)
+	e.companyInfo(r.CompanyInfo)
+	e.annotations(r.Annotations)
+
+	return e.buf, nil
+}
+
+//UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (r *ResponseVendorName) UnmarshalBinary(data []byte) error {
+	d := decoder{buf: data}
+
+	d.readVersion()
+	resp := ResponseVendorName{RespTime: time.Duration(d.varint())}
+	resp.RateLimit = d.rateLimit()
+	resp.CompanyInfo = d.companyInfo()
+	resp.Annotations = d.annotations()
+
+	if err := d.end(); err != nil {
+		return err
+	}
+
+	*r = resp
+
+	return nil
+}
+
+// newAnnotationsWire omits the zero annotations from the JSON encoding.
+func newAnnotationsWire(a Annotations) annotationsWire {
+	w := annotationsWire{Owners: a.Owners}
+
+	if a.Vendor != (Vendor{}) {
+		w.Vendor = &a.Vendor
+	}
+
+	if a.Classification != (Classification{}) {
+		w.Classification = &a.Classification
+	}
+
+	if a.Virtual != (VirtualNIC{}) {
+		w.Virtual = &a.Virtual
+	}
+
+	return w
+}
+
+func (w annotationsWire) annotations() Annotations {
+	a := Annotations{Owners: w.Owners}
+
+	if w.Vendor != nil {
+		a.Vendor = *w.Vendor
+	}
+
+	if w.Classification != nil {
+		a.Classification = *w.Classification
+	}
+
+	if w.Virtual != nil {
+		a.Virtual = *w.Virtual
+	}
+
+	return a
+}
+
+func checkSchemaVersion(v int) error {
+	if v < 0 || v > SchemaVersion {
+		return fmt.Errorf("%w %d", ErrSchemaVersion, v)
+	}
+
+	return nil
+}
+
+// encoder writes the binary encoding: varints, length prefixed strings and one byte booleans.
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) version() {
+	e.uvarint(SchemaVersion)
+}
+
+func (e *encoder) uvarint(v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	e.buf = append(e.buf, b[:binary.PutUvarint(b[:], v)]...)
+}
+
+func (e *encoder) varint(v int64) {
+	var b [binary.MaxVarintLen64]byte
+	e.buf = append(e.buf, b[:binary.PutVarint(b[:], v)]...)
+}
+
+func (e *encoder) bool(v bool) {
+	if v {
+		e.buf = append(e.buf, 1)
+		return
+	}
+
+	e.buf = append(e.buf, 0)
+}
+
+func (e *encoder) string(s string) {
+	e.uvarint(uint64(len(s)))
+	e.buf = append(e.buf, s...)
+}
+
+func (e *encoder) time(t time.Time) {
+	e.bool(!t.IsZero())
+
+	if !t.IsZero() {
+		e.varint(t.Unix())
+		e.uvarint(uint64(t.Nanosecond()))
+	}
+}
+
+func (e *encoder) rateLimit(r RateLimit) {
+	e.varint(r.Limit)
+	e.varint(r.Remaining)
+	e.time(r.Reset)
+}
+
+func (e *encoder) macInfo(m MACInfo) {
+	e.bool(m.Found)
+	e.string(m.MacPrefix)
+	e.string(m.Company)
+	e.string(m.Address)
+	e.string(m.Country)
+	e.string(m.BlockStart)
+	e.string(m.BlockEnd)
+	e.varint(int64(m.BlockSize))
+	e.string(m.BlockType)
+	e.string(m.Updated)
+	e.bool(m.IsRand)
+	e.bool(m.IsPrivate)
+}
+
+func (e *encoder) companyInfo(c CompanyInfo) {
+	e.bool(c.Found)
+	e.bool(c.IsPrivate)
+	e.string(c.Company)
+}
+
+func (e *encoder) annotations(a Annotations) {
+	e.vendor(a.Vendor)
+	e.vendors(a.Owners)
+	e.string(string(a.Classification.Category))
+	e.float(a.Classification.Confidence)
+	e.string(string(a.Virtual.Platform))
+	e.string(string(a.Virtual.Kind))
+	e.float(a.Virtual.Confidence)
+}
+
+func (e *encoder) float(f float64) {
+	e.uvarint(math.Float64bits(f))
+}
+
+func (e *encoder) vendor(v Vendor) {
+	e.string(v.Key)
+	e.string(v.Name)
+}
+
+func (e *encoder) vendors(v []Vendor) {
+	e.uvarint(uint64(len(v)))
+
+	for _, vendor := range v {
+		e.vendor(vendor)
+	}
+}
+
+// annotatedVersion is the first SchemaVersion with the Annotations of the responses.
+const annotatedVersion = 2
+
+// decoder reads the encoding of encoder. The first error is kept and returned by end.
+type decoder struct {
+	buf     []byte
+	err     error
+	version uint64
+}
+
+var errShortBuffer = errors.New("binary data too short")
+
+func (d *decoder) readVersion() {
+	d.version = d.uvarint()
+	if d.err == nil {
+		d.err = checkSchemaVersion(int(d.version))
+	}
+}
+
+func (d *decoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+
+	v, n := binary.Uvarint(d.buf)
+	if n <= 0 {
+		d.err = errShortBuffer
+		return 0
+	}
+
+	d.buf = d.buf[n:]
+
+	return v
+}
+
+func (d *decoder) varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+
+	v, n := binary.Varint(d.buf)
+	if n <= 0 {
+		d.err = errShortBuffer
+		return 0
+	}
+
+	d.buf = d.buf[n:]
+
+	return v
+}
+
+func (d *decoder) bool() bool {
+	if d.err != nil {
+		return false
+	}
+
+	if len(d.buf) == 0 {
+		d.err = errShortBuffer
+		return false
+	}
+
+	v := d.buf[0]
+	d.buf = d.buf[1:]
+
+	return v != 0
+}
+
+func (d *decoder) string() string {
+	n := d.uvarint()
+	if d.err != nil {
+		return ""
+	}
+
+	if uint64(len(d.buf)) < n {
+		d.err = errShortBuffer
+		return ""
+	}
+
+	s := string(d.buf[:n])
+	d.buf = d.buf[n:]
+
+	return s
+}
+
+func (d *decoder) time() time.Time {
+	if !d.bool() {
+		return time.Time{}
+	}
+
+	sec := d.varint()
+	nsec := d.uvarint()
+
+	if d.err != nil {
+		return time.Time{}
+	}
+
+	return time.Unix(sec, int64(nsec))
+}
+
+func (d *decoder) rateLimit() RateLimit {
+	return RateLimit{
+		Limit:     d.varint(),
+		Remaining: d.varint(),
+		Reset:     d.time(),
+	}
+}
+
+func (d *decoder) macInfo() MACInfo {
+	return MACInfo{
+		Found:      d.bool(),
+		MacPrefix:  d.string(),
+		Company:    d.string(),
+		Address:    d.string(),
+		Country:    d.string(),
+		BlockStart: d.string(),
+		BlockEnd:   d.string(),
+		BlockSize:  int(d.varint()),
+		BlockType:  d.string(),
+		Updated:    d.string(),
+		IsRand:     d.bool(),
+		IsPrivate:  d.bool(),
+	}
+}
+
+func (d *decoder) companyInfo() CompanyInfo {
+	return CompanyInfo{
+		Found:     d.bool(),
+		IsPrivate: d.bool(),
+		Company:   d.string(),
+	}
+}
+
+// annotations reads the Annotations, missing before annotatedVersion.
+func (d *decoder) annotations() Annotations {
+	if d.version < annotatedVersion {
+		return Annotations{}
+	}
+
+	return Annotations{
+		Vendor: d.vendor(),
+		Owners: d.vendors(),
+		Classification: Classification{
+			Category:   Category(d.string()),
+			Confidence: d.float(),
+		},
+		Virtual: VirtualNIC{
+			Platform:   Platform(d.string()),
+			Kind:       VirtualKind(d.string()),
+			Confidence: d.float(),
+		},
+	}
+}
+
+func (d *decoder) float() float64 {
+	return math.Float64frombits(d.uvarint())
+}
+
+func (d *decoder) vendor() Vendor {
+	return Vendor{
+		Key:  d.string(),
+		Name: d.string(),
+	}
+}
+
+// vendors returns nil for an empty list, as the JSON encoding.
+func (d *decoder) vendors() []Vendor {
+	n := d.uvarint()
+	if d.err != nil || n == 0 {
+		return nil
+	}
+
+	if n > uint64(len(d.buf)) {
+		d.err = errShortBuffer
+		return nil
+	}
+
+	v := make([]Vendor, n)
+	for i := range v {
+		v[i] = d.vendor()
+	}
+
+	return v
+}
+
+func (d *decoder) end() error {
+	if d.err == nil && len(d.buf) > 0 {
+		return errors.New("trailing binary data")
+	}
+
+	return d.err
+}
@@ -0,0 +1,180 @@
+package maclookup
+
+import (
+	"encoding"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testRateLimit = RateLimit{Limit: 10, Remaining: 7, Reset: time.Unix(1700000000, 123456789)}
+	testMACInfo   = MACInfo{
+		Found:      true,
+		MacPrefix:  "000000",
+		Company:    "XEROX CORPORATION",
+		Address:    "M/S 105-50C, WEBSTER NY 14580, US",
+		Country:    "US",
+		BlockStart: "000000000000",
+		BlockEnd:   "000000FFFFFF",
+		BlockSize:  16777215,
+		BlockType:  "MA-L",
+		Updated:    "2015-11-17",
+		IsRand:     true,
+		IsPrivate:  true,
+	}
+	testCompanyInfo = CompanyInfo{Found: true, IsPrivate: true, Company: "XEROX CORPORATION"}
+	testAnnotations = Annotations{
+		Vendor:         Vendor{Key: "xerox", Name: "Xerox"},
+		Owners:         []Vendor{{Key: "xerox", Name: "Xerox"}, {Key: "fujifilm", Name: "Fujifilm"}},
+		Classification: Classification{Category: CategoryPrinter, Confidence: 0.8},
+		Virtual:        VirtualNIC{Platform: PlatformVMware, Kind: KindVM, Confidence: 1},
+	}
+)
+
+type marshaler interface {
+	json.Marshaler
+	encoding.TextMarshaler
+	encoding.BinaryMarshaler
+}
+
+type unmarshaler interface {
+	json.Unmarshaler
+	encoding.TextUnmarshaler
+	encoding.BinaryUnmarshaler
+}
+
+func TestMarshal_roundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		in   marshaler
+		out  func() unmarshaler
+	}{
+		{"RateLimit", testRateLimit, func() unmarshaler { return &RateLimit{} }},
+		{"RateLimitZero", RateLimit{Limit: -1, Remaining: -1}, func() unmarshaler { return &RateLimit{} }},
+		{"MACInfo", testMACInfo, func() unmarshaler { return &MACInfo{} }},
+		{"CompanyInfo", testCompanyInfo, func() unmarshaler { return &CompanyInfo{} }},
+		{"ResponseMACInfo", ResponseMACInfo{RespTime: 1234567 * time.Nanosecond, RateLimit: testRateLimit, MACInfo: testMACInfo, Annotations: testAnnotations}, func() unmarshaler { return &ResponseMACInfo{} }},
+		{"ResponseVendorName", ResponseVendorName{RespTime: time.Second, RateLimit: testRateLimit, CompanyInfo: testCompanyInfo, Annotations: Annotations{Vendor: Vendor{Key: "xerox", Name: "Xerox"}}}, func() unmarshaler { return &ResponseVendorName{} }},
+		{"ResponseVendorNameZero", ResponseVendorName{}, func() unmarshaler { return &ResponseVendorName{} }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tt.in.MarshalJSON()
+			assert.Nil(t, err)
+
+			out := tt.out()
+			assert.Nil(t, json.Unmarshal(data, out))
+			assert.Equal(t, tt.in, deref(out))
+
+			text, err := tt.in.MarshalText()
+			assert.Nil(t, err)
+
+			out = tt.out()
+			assert.Nil(t, out.UnmarshalText(text))
+			assert.Equal(t, tt.in, deref(out))
+
+			bin, err := tt.in.MarshalBinary()
+			assert.Nil(t, err)
+
+			out = tt.out()
+			assert.Nil(t, out.UnmarshalBinary(bin))
+			assert.Equal(t, tt.in, deref(out))
+
+			out = tt.out()
+			assert.NotNil(t, out.UnmarshalBinary(bin[:len(bin)-1]))
+			assert.NotNil(t, out.UnmarshalBinary(append(bin, 0)))
+		})
+	}
+}
+
+func deref(u unmarshaler) interface{} {
+	switch v := u.(type) {
+	case *RateLimit:
+		return *v
+	case *MACInfo:
+		return *v
+	case *CompanyInfo:
+		return *v
+	case *ResponseMACInfo:
+		return *v
+	case *ResponseVendorName:
+		return *v
+	}
+
+	return nil
+}
+
+func TestMarshal_wireFormat(t *testing.T) {
+	resp := ResponseVendorName{
+		RespTime:    1500 * time.Millisecond,
+		RateLimit:   RateLimit{Limit: 2, Remaining: 1, Reset: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)},
+		CompanyInfo: CompanyInfo{Found: true, Company: "XEROX CORPORATION"},
+	}
+
+	data, err := json.Marshal(resp)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{
+		"schemaVersion": 2,
+		"respTimeNs": 1500000000,
+		"rateLimit": {"limit": 2, "remaining": 1, "reset": "2026-01-02T03:04:05Z"},
+		"companyInfo": {"found": true, "isPrivate": false, "company": "XEROX CORPORATION"}
+	}`, string(data))
+
+	resp.Vendor = Vendor{Key: "xerox", Name: "Xerox"}
+
+	data, err = json.Marshal(resp)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"vendor":{"key":"xerox","name":"Xerox"}`)
+	assert.NotContains(t, string(data), `"owners"`)
+	assert.NotContains(t, string(data), `"classification"`)
+	assert.NotContains(t, string(data), `"virtual"`)
+
+	data, err = json.Marshal(MACInfo{MacPrefix: "000000"})
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"found":false,"macPrefix":"000000","company":"","address":"","country":"","blockStart":"","blockEnd":"","blockSize":0,"blockType":"","updated":"","isRand":false,"isPrivate":false}`, string(data))
+
+	data, err = json.Marshal(RateLimit{Limit: -1, Remaining: -1})
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"limit":-1,"remaining":-1}`, string(data))
+
+	data, err = json.Marshal(ResponseMACInfo{RateLimit: RateLimit{Limit: -1, Remaining: -1}})
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"rateLimit":{"limit":-1,"remaining":-1}`)
+}
+
+func TestMarshal_schemaVersion(t *testing.T) {
+	var resp ResponseMACInfo
+
+	err := json.Unmarshal([]byte(`{"schemaVersion":3,"macInfo":{"found":true}}`), &resp)
+	assert.True(t, errors.Is(err, ErrSchemaVersion))
+
+	err = resp.UnmarshalBinary([]byte{3})
+	assert.True(t, errors.Is(err, ErrSchemaVersion))
+
+	assert.Nil(t, json.Unmarshal([]byte(`{"macInfo":{"found":true}}`), &resp))
+	assert.True(t, resp.Found)
+}
+
+func TestMarshal_version1(t *testing.T) {
+	// ResponseMACInfo written with version 1, before the Annotations.
+	data, err := hex.DecodeString("0180bcc1960b040201cad6b9950d000106303030303030115845524f5820434f52504f524154494f4e00025553000000044d412d4c000000")
+	assert.Nil(t, err)
+
+	var resp ResponseMACInfo
+
+	assert.Nil(t, resp.UnmarshalBinary(data))
+	assert.Equal(t, ResponseMACInfo{
+		RespTime:  1500 * time.Millisecond,
+		RateLimit: RateLimit{Limit: 2, Remaining: 1, Reset: time.Unix(1767323045, 0)},
+		MACInfo:   MACInfo{Found: true, MacPrefix: "000000", Company: "XEROX CORPORATION", Country: "US", BlockType: "MA-L"},
+	}, resp)
+
+	// Version 1 data followed by annotations is rejected.
+	assert.NotNil(t, resp.UnmarshalBinary(append(data, 0, 0)))
+}
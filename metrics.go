@@ -0,0 +1,260 @@
+package maclookup
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+//Endpoints reported in metrics.
+const (
+	EndpointLookup      = "lookup"
+	EndpointCompanyName = "company_name"
+)
+
+//Outcomes reported in metrics.
+const (
+	OutcomeOK             = "ok"
+	OutcomeNotFound       = "not_found"
+	OutcomePrivate        = "private"
+	OutcomeBadRequest     = "bad_request"
+	OutcomeBadKey         = "bad_key"
+	OutcomeRateLimited    = "rate_limited"
+	OutcomeTransportError = "transport_error"
+	OutcomeBadResponse    = "bad_response"
+	OutcomeCircuitOpen    = "circuit_open"
+)
+
+//DefaultLatencyBuckets are the upper bounds, in seconds, of the latency histogram.
+var DefaultLatencyBuckets = []float64{0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+//Metrics collects request counts, latencies and rate limits of a Client
+//and exposes them in the Prometheus text exposition format.
+type Metrics struct {
+	mu        sync.Mutex
+	buckets   []float64
+	requests  map[requestKey]uint64
+	latencies map[string]*histogram
+	rateLimit RateLimit
+	seenLimit bool
+}
+
+type requestKey struct {
+	endpoint string
+	outcome  string
+}
+
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+//NewMetrics creates a collector using DefaultLatencyBuckets.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		buckets:   DefaultLatencyBuckets,
+		requests:  make(map[requestKey]uint64),
+		latencies: make(map[string]*histogram),
+	}
+}
+
+//WithMetrics records the metrics of every request in m. The same Metrics can be shared by several clients.
+func (c *Client) WithMetrics(m *Metrics) {
+	c.metrics = m
+}
+
+//Outcome classifies the result of a request.
+func Outcome(found, private bool, err error) string {
+	var (
+		requestErr   *BadAPIRequest
+		keyErr       *BadAPIKey
+		rateErr      *RateLimitsExceeded
+		responseErr  *BadAPIResponse
+		transportErr *HTTPClientError
+	)
+
+	switch {
+	case errors.Is(err, ErrCircuitOpen):
+		return OutcomeCircuitOpen
+	case err == nil && private:
+		return OutcomePrivate
+	case err == nil && !found:
+		return OutcomeNotFound
+	case err == nil:
+		return OutcomeOK
+	case errors.As(err, &requestErr):
+		return OutcomeBadRequest
+	case errors.As(err, &keyErr):
+		return OutcomeBadKey
+	case errors.As(err, &rateErr):
+		return OutcomeRateLimited
+	case errors.As(err, &responseErr):
+		return OutcomeBadResponse
+	case errors.As(err, &transportErr):
+		return OutcomeTransportError
+	}
+
+	return OutcomeTransportError
+}
+
+func (m *Metrics) observe(endpoint, outcome string, latency time.Duration, rl RateLimit) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests[requestKey{endpoint: endpoint, outcome: outcome}]++
+
+	h, ok := m.latencies[endpoint]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(m.buckets))}
+		m.latencies[endpoint] = h
+	}
+
+	seconds := latency.Seconds()
+	for i, b := range m.buckets {
+		if seconds <= b {
+			h.counts[i]++
+		}
+	}
+
+	h.sum += seconds
+	h.count++
+
+	// Failed requests and responses without rate limit headers must not reset the gauges.
+	if rl.Limit >= 0 && rl.Remaining >= 0 && !rl.Reset.IsZero() {
+		m.rateLimit = rl
+		m.seenLimit = true
+	}
+}
+
+// reject counts a request that was not sent, without latency.
+func (m *Metrics) reject(endpoint, outcome string) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests[requestKey{endpoint: endpoint, outcome: outcome}]++
+}
+
+//RateLimit returns the last rate limit seen in a response.
+func (m *Metrics) RateLimit() RateLimit {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.rateLimit
+}
+
+//Requests returns the number of requests recorded for endpoint and outcome.
+func (m *Metrics) Requests(endpoint, outcome string) uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.requests[requestKey{endpoint: endpoint, outcome: outcome}]
+}
+
+//ServeHTTP writes the metrics in the Prometheus text exposition format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = m.Export(w)
+}
+
+//Export writes the metrics in the Prometheus text exposition format.
+func (m *Metrics) Export(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ew := &errWriter{w: w}
+
+	ew.printf("# HELP maclookup_requests_total Requests sent to the MACLookup API by endpoint and outcome.\n")
+	ew.printf("# TYPE maclookup_requests_total counter\n")
+
+	keys := make([]requestKey, 0, len(m.requests))
+	for k := range m.requests {
+		keys = append(keys, k)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].endpoint != keys[j].endpoint {
+			return keys[i].endpoint < keys[j].endpoint
+		}
+
+		return keys[i].outcome < keys[j].outcome
+	})
+
+	for _, k := range keys {
+		ew.printf("maclookup_requests_total{endpoint=%q,outcome=%q} %d\n", k.endpoint, k.outcome, m.requests[k])
+	}
+
+	ew.printf("# HELP maclookup_request_duration_seconds Response time of the MACLookup API.\n")
+	ew.printf("# TYPE maclookup_request_duration_seconds histogram\n")
+
+	endpoints := make([]string, 0, len(m.latencies))
+	for e := range m.latencies {
+		endpoints = append(endpoints, e)
+	}
+
+	sort.Strings(endpoints)
+
+	for _, e := range endpoints {
+		h := m.latencies[e]
+		for i, b := range m.buckets {
+			ew.printf("maclookup_request_duration_seconds_bucket{endpoint=%q,le=%q} %d\n", e, formatFloat(b), h.counts[i])
+		}
+
+		ew.printf("maclookup_request_duration_seconds_bucket{endpoint=%q,le=\"+Inf\"} %d\n", e, h.count)
+		ew.printf("maclookup_request_duration_seconds_sum{endpoint=%q} %s\n", e, formatFloat(h.sum))
+		ew.printf("maclookup_request_duration_seconds_count{endpoint=%q} %d\n", e, h.count)
+	}
+
+	if m.seenLimit {
+		ew.printf("# HELP maclookup_rate_limit_limit Request limit of the current rate limit window.\n")
+		ew.printf("# TYPE maclookup_rate_limit_limit gauge\n")
+		ew.printf("maclookup_rate_limit_limit %d\n", m.rateLimit.Limit)
+		ew.printf("# HELP maclookup_rate_limit_remaining Requests left in the current rate limit window.\n")
+		ew.printf("# TYPE maclookup_rate_limit_remaining gauge\n")
+		ew.printf("maclookup_rate_limit_remaining %d\n", m.rateLimit.Remaining)
+		ew.printf("# HELP maclookup_rate_limit_reset_timestamp_seconds Unix time of the next rate limit reset.\n")
+		ew.printf("# TYPE maclookup_rate_limit_reset_timestamp_seconds gauge\n")
+		ew.printf("maclookup_rate_limit_reset_timestamp_seconds %d\n", m.rateLimit.Reset.Unix())
+	}
+
+	return ew.err
+}
+
+// elapsed returns respTime, or the time since start for requests that failed before RespTime was set.
+func elapsed(respTime time.Duration, start time.Time) time.Duration {
+	if respTime > 0 {
+		return respTime
+	}
+
+	return time.Since(start)
+}
+
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ew *errWriter) printf(format string, args ...interface{}) {
+	if ew.err != nil {
+		return
+	}
+
+	_, ew.err = fmt.Fprintf(ew.w, format, args...)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,92 @@
+package maclookup
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_WithMetrics(t *testing.T) {
+	now := time.Now()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add(xRateLimit, "10")
+		w.Header().Add(xRateRemaining, "7")
+		w.Header().Add(xRateReset, fmt.Sprintf("%d", now.Unix()))
+
+		switch r.URL.Path {
+		case "/v2/macs/000000":
+			fmt.Fprintln(w, `{"success":true,"found":true,"macPrefix":"000000","company":"XEROX CORPORATION","blockType":"MA-L"}`)
+		case "/v2/macs/010000":
+			fmt.Fprintln(w, `{"success":true,"found":false,"isRand":false}`)
+		case "/v2/macs/ACDE48/company/name":
+			fmt.Fprint(w, `*PRIVATE*`)
+		case "/v2/macs/000000/company/name":
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer ts.Close()
+
+	m := NewMetrics()
+	client := New()
+	client.WithPrefixURI(ts.URL)
+	client.WithMetrics(m)
+
+	_, _ = client.Lookup("000000")
+	_, _ = client.Lookup("000000")
+	_, _ = client.Lookup("010000")
+	_, _ = client.Lookup("020000")
+	_, _ = client.CompanyName("ACDE48")
+	_, _ = client.CompanyName("000000")
+
+	assert.Equal(t, uint64(2), m.Requests(EndpointLookup, OutcomeOK))
+	assert.Equal(t, uint64(1), m.Requests(EndpointLookup, OutcomeNotFound))
+	assert.Equal(t, uint64(1), m.Requests(EndpointLookup, OutcomeBadKey))
+	assert.Equal(t, uint64(1), m.Requests(EndpointCompanyName, OutcomePrivate))
+	assert.Equal(t, uint64(1), m.Requests(EndpointCompanyName, OutcomeRateLimited))
+	assert.Equal(t, RateLimit{Limit: 10, Remaining: 7, Reset: time.Unix(now.Unix(), 0)}, m.RateLimit())
+
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body := w.Body.String()
+	assert.True(t, strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain; version=0.0.4"))
+	assert.Contains(t, body, `maclookup_requests_total{endpoint="lookup",outcome="ok"} 2`+"\n")
+	assert.Contains(t, body, `maclookup_requests_total{endpoint="company_name",outcome="rate_limited"} 1`+"\n")
+	assert.Contains(t, body, `maclookup_request_duration_seconds_bucket{endpoint="lookup",le="+Inf"} 4`+"\n")
+	assert.Contains(t, body, `maclookup_request_duration_seconds_count{endpoint="company_name"} 2`+"\n")
+	assert.Contains(t, body, "maclookup_rate_limit_remaining 7\n")
+	assert.Contains(t, body, fmt.Sprintf("maclookup_rate_limit_reset_timestamp_seconds %d\n", now.Unix()))
+}
+
+func TestClient_WithMetricsTransportError(t *testing.T) {
+	m := NewMetrics()
+	client := New()
+	client.WithPrefixURI("http://127.0.0.1:1")
+	client.WithMetrics(m)
+
+	_, err := client.Lookup("000000")
+	assert.NotNil(t, err)
+	assert.Equal(t, uint64(1), m.Requests(EndpointLookup, OutcomeTransportError))
+
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.NotContains(t, w.Body.String(), "maclookup_rate_limit")
+}
+
+func TestOutcome(t *testing.T) {
+	assert.Equal(t, OutcomeOK, Outcome(true, false, nil))
+	assert.Equal(t, OutcomePrivate, Outcome(true, true, nil))
+	assert.Equal(t, OutcomeNotFound, Outcome(false, false, nil))
+	assert.Equal(t, OutcomeBadRequest, Outcome(false, false, &BadAPIRequest{Err: errors.New("e")}))
+	assert.Equal(t, OutcomeBadResponse, Outcome(false, false, &BadAPIResponse{Err: errors.New("e")}))
+	assert.Equal(t, OutcomeTransportError, Outcome(false, false, &HTTPClientError{Err: errors.New("e")}))
+	assert.Equal(t, OutcomeTransportError, Outcome(false, false, errors.New("e")))
+}
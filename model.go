@@ -2,41 +2,62 @@ package maclookup
 
 import "time"
 
+//ResponseMACInfo is the result of Lookup.
+//Its JSON encoding is {"schemaVersion":2,"respTimeNs":...,"rateLimit":{...},"macInfo":{...}}, followed by the Annotations.
 type ResponseMACInfo struct {
 	RespTime time.Duration
 	RateLimit
 	MACInfo
+	Annotations
 }
 
+//ResponseVendorName is the result of CompanyName.
+//Its JSON encoding is {"schemaVersion":2,"respTimeNs":...,"rateLimit":{...},"companyInfo":{...}}, followed by the Annotations.
 type ResponseVendorName struct {
 	RespTime time.Duration
 	RateLimit
 	CompanyInfo
+	Annotations
 }
 
+//Annotations are computed by the client from the company name and the MAC address of a result:
+//Virtual always (see DetectVirtualInfo), Vendor, Owners and Classification when it has a Normalizer,
+//an Ownership or a Classifier. In JSON they are the optional "vendor", "owners", "classification" and "virtual" fields.
+type Annotations struct {
+	Vendor         Vendor
+	Owners         []Vendor
+	Classification Classification
+	Virtual        VirtualNIC
+}
+
+//RateLimit is the rate limit state sent by the API with every response.
+//In the JSON encoding of the responses, Reset is an RFC 3339 timestamp, omitted when zero.
 type RateLimit struct {
-	Limit     int64
-	Remaining int64
-	Reset     time.Time
+	Limit     int64     `json:"limit"`
+	Remaining int64     `json:"remaining"`
+	Reset     time.Time `json:"reset"`
 }
 
 type MACInfo struct {
-	Found      bool
-	MacPrefix  string
-	Company    string
-	Address    string
-	Country    string
-	BlockStart string
-	BlockEnd   string
-	BlockSize  int
-	BlockType  string
-	Updated    string
-	IsRand     bool
-	IsPrivate  bool
+	Found      bool   `json:"found"`
+	MacPrefix  string `json:"macPrefix"`
+	Company    string `json:"company"`
+	Address    string `json:"address"`
+	Country    string `json:"country"`
+	BlockStart string `json:"blockStart"`
+	BlockEnd   string `json:"blockEnd"`
+	BlockSize  int    `json:"blockSize"`
+	BlockType  string `json:"blockType"`
+	Updated    string `json:"updated"`
+	IsRand     bool   `json:"isRand"`
+	IsPrivate  bool   `json:"isPrivate"`
 }
 
+//VendorPrivate is the vendor name shown for prefixes registered as private, which have no company.
+const VendorPrivate = "(private)"
+
 type CompanyInfo struct {
-	Found     bool
-	IsPrivate bool
-	Company   string
+	Found     bool   `json:"found"`
+	IsPrivate bool   `json:"isPrivate"`
+	Company   string `json:"company"`
 }
@@ -0,0 +1,3 @@
+// Package nmap fills or corrects the vendor of MAC addresses in nmap XML scans
+// using the MACLookup API, and reports the registry block type of each address.
+package nmap
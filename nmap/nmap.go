@@ -0,0 +1,122 @@
+package nmap
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"net"
+
+	"github.com/logocomune/maclookup-go"
+)
+
+//Result describes the enrichment of one MAC address element of the scan.
+type Result struct {
+	MAC       string
+	OldVendor string
+	Vendor    string
+	BlockType string
+	Found     bool
+	IsPrivate bool
+	IsRand    bool
+}
+
+//Changed reports whether the vendor attribute was filled or corrected.
+func (r Result) Changed() bool {
+	return r.OldVendor != r.Vendor
+}
+
+//Enrich copies an nmap XML scan from r to w, filling or correcting the vendor
+//attribute of every <address addrtype="mac"> element with the company returned by Lookup.
+//Vendors of prefixes not found in the registry, or registered as private, are left untouched.
+//It returns one Result per MAC address element, in document order.
+func Enrich(r io.Reader, w io.Writer, res maclookup.MACInfoResolver) ([]Result, error) {
+	dec := xml.NewDecoder(r)
+	enc := xml.NewEncoder(w)
+
+	var results []Result
+
+	cache := make(map[string]maclookup.MACInfo)
+
+	for {
+		tok, err := dec.RawToken()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return results, err
+		}
+
+		if start, ok := tok.(xml.StartElement); ok && start.Name.Local == "address" && attr(start, "addrtype") == "mac" {
+			result, err := enrichAddress(&start, res, cache)
+			if err != nil {
+				return results, err
+			}
+
+			results = append(results, result)
+			tok = start
+		}
+
+		if err := enc.EncodeToken(tok); err != nil {
+			return results, err
+		}
+	}
+
+	return results, enc.Flush()
+}
+
+func enrichAddress(start *xml.StartElement, res maclookup.MACInfoResolver, cache map[string]maclookup.MACInfo) (Result, error) {
+	mac := attr(*start, "addr")
+	result := Result{MAC: mac, OldVendor: attr(*start, "vendor")}
+	result.Vendor = result.OldVendor
+
+	if _, err := net.ParseMAC(mac); err != nil {
+		return result, nil
+	}
+
+	key := maclookup.Prefix(mac)
+
+	info, ok := cache[key]
+	if !ok {
+		resp, err := res.Lookup(mac)
+		if err != nil {
+			return result, err
+		}
+
+		info = resp.MACInfo
+		cache[key] = info
+	}
+
+	result.Found = info.Found
+	result.IsPrivate = info.IsPrivate
+	result.IsRand = info.IsRand
+	result.BlockType = info.BlockType
+
+	if info.Found && !info.IsPrivate && info.Company != "" {
+		result.Vendor = info.Company
+		setAttr(start, "vendor", info.Company)
+	}
+
+	return result, nil
+}
+
+func attr(start xml.StartElement, name string) string {
+	for _, a := range start.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+
+	return ""
+}
+
+func setAttr(start *xml.StartElement, name, value string) {
+	for i, a := range start.Attr {
+		if a.Name.Local == name {
+			start.Attr[i].Value = value
+			return
+		}
+	}
+
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: name}, Value: value})
+}
@@ -0,0 +1,85 @@
+package nmap
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/logocomune/maclookup-go"
+	"github.com/stretchr/testify/assert"
+)
+
+const scan = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE nmaprun>
+<nmaprun scanner="nmap" args="nmap -sn 192.168.1.0/24">
+<host><status state="up" reason="arp-response"/>
+<address addr="192.168.1.1" addrtype="ipv4"/>
+<address addr="00:00:00:11:22:33" addrtype="mac" vendor="Xerox"/>
+</host>
+<host><status state="up" reason="arp-response"/>
+<address addr="192.168.1.2" addrtype="ipv4"/>
+<address addr="00:00:00:44:55:66" addrtype="mac"/>
+</host>
+<host><status state="up" reason="arp-response"/>
+<address addr="192.168.1.3" addrtype="ipv4"/>
+<address addr="02:42:AC:11:00:02" addrtype="mac" vendor="Unknown"/>
+</host>
+</nmaprun>
+`
+
+type fakeResolver struct {
+	calls int
+	err   error
+}
+
+func (f *fakeResolver) Lookup(mac string) (maclookup.ResponseMACInfo, error) {
+	f.calls++
+
+	var r maclookup.ResponseMACInfo
+
+	if strings.HasPrefix(mac, "00:00:00") {
+		r.Found, r.Company, r.BlockType = true, "XEROX CORPORATION", "MA-L"
+	} else {
+		r.IsRand = true
+	}
+
+	return r, f.err
+}
+
+func TestEnrich(t *testing.T) {
+	var out bytes.Buffer
+
+	r := &fakeResolver{}
+	results, err := Enrich(strings.NewReader(scan), &out, r)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 3, r.calls)
+	assert.Len(t, results, 3)
+
+	assert.Equal(t, Result{MAC: "00:00:00:11:22:33", OldVendor: "Xerox", Vendor: "XEROX CORPORATION", BlockType: "MA-L", Found: true}, results[0])
+	assert.True(t, results[0].Changed())
+	assert.Equal(t, "", results[1].OldVendor)
+	assert.Equal(t, "XEROX CORPORATION", results[1].Vendor)
+	assert.False(t, results[2].Changed())
+	assert.True(t, results[2].IsRand)
+
+	assert.Contains(t, out.String(), `<address addr="00:00:00:11:22:33" addrtype="mac" vendor="XEROX CORPORATION">`)
+	assert.Contains(t, out.String(), `<address addr="00:00:00:44:55:66" addrtype="mac" vendor="XEROX CORPORATION">`)
+	assert.Contains(t, out.String(), `<address addr="02:42:AC:11:00:02" addrtype="mac" vendor="Unknown">`)
+	assert.Contains(t, out.String(), `<address addr="192.168.1.1" addrtype="ipv4">`)
+	assert.Contains(t, out.String(), `<!DOCTYPE nmaprun>`)
+}
+
+func TestEnrich_Error(t *testing.T) {
+	var out bytes.Buffer
+
+	_, err := Enrich(strings.NewReader(scan), &out, &fakeResolver{err: &maclookup.BadAPIKey{Err: errors.New("bad api key")}})
+
+	var e *maclookup.BadAPIKey
+
+	assert.True(t, errors.As(err, &e))
+
+	_, err = Enrich(strings.NewReader("<nmaprun><host></nmaprun>"), &out, &fakeResolver{})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,218 @@
+package maclookup
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+//Vendor is the canonical identity of a company: Key groups the spellings of the same company
+//("Apple, Inc.", "APPLE INC" and "Apple Inc" are "apple") and Name is its display name.
+type Vendor struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
+}
+
+// legalSuffixes are the legal forms stripped from the end of company names, as token sequences.
+var legalSuffixes = [][]string{
+	{"inc"}, {"incorporated"}, {"corp"}, {"corporation"}, {"co"}, {"company"}, {"cos"},
+	{"ltd"}, {"limited"}, {"llc"}, {"llp"}, {"lp"}, {"plc"}, {"pte"}, {"pty"}, {"pvt"}, {"private"},
+	{"gmbh"}, {"mbh"}, {"ag"}, {"kg"}, {"ohg"}, {"ug"}, {"se"},
+	{"sa"}, {"s", "a"}, {"sas"}, {"s", "a", "s"}, {"sarl"}, {"s", "a", "r", "l"}, {"sl"}, {"s", "l"},
+	{"spa"}, {"s", "p", "a"}, {"srl"}, {"s", "r", "l"},
+	{"bv"}, {"b", "v"}, {"nv"}, {"n", "v"}, {"oy"}, {"oyj"}, {"ab"}, {"as"}, {"a", "s"}, {"asa"}, {"aps"},
+	{"kk"}, {"k", "k"}, {"sdn", "bhd"}, {"bhd"}, {"tbk"}, {"jsc"}, {"ooo"},
+}
+
+//Normalizer maps company names to a canonical Vendor, ignoring case, punctuation,
+//white space and legal suffixes (Inc, Ltd, GmbH, Co., Ltd., ...). An alias table overrides the result.
+type Normalizer struct {
+	mu      sync.RWMutex
+	aliases map[string]Vendor
+}
+
+//NewNormalizer creates a Normalizer without aliases.
+func NewNormalizer() *Normalizer {
+	return &Normalizer{aliases: make(map[string]Vendor)}
+}
+
+//AddAlias makes every spelling of variant normalize to canonical, e.g. AddAlias("Hewlett Packard", "HP").
+func (n *Normalizer) AddAlias(variant, canonical string) {
+	v := NormalizeCompany(canonical)
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.aliases[NormalizeCompany(variant).Key] = v
+	n.aliases[v.Key] = v
+}
+
+//LoadAliases adds the aliases of a two column CSV stream (variant,canonical).
+//Empty lines and lines starting with # are skipped.
+func (n *Normalizer) LoadAliases(r io.Reader) error {
+	cr := csv.NewReader(r)
+	cr.Comment = '#'
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	for {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if len(rec) != 2 {
+			line, _ := cr.FieldPos(0)
+			return &csv.ParseError{StartLine: line, Line: line, Err: errors.New("alias must have two fields: variant,canonical")}
+		}
+
+		n.AddAlias(rec[0], rec[1])
+	}
+}
+
+//Normalize returns the canonical Vendor of company, or the zero Vendor for an empty name.
+func (n *Normalizer) Normalize(company string) Vendor {
+	v := NormalizeCompany(company)
+	if v.Key == "" || n == nil {
+		return v
+	}
+
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	if alias, ok := n.aliases[v.Key]; ok {
+		return alias
+	}
+
+	return v
+}
+
+//WithNormalizer sets the Vendor of every result from its company name.
+func (c *Client) WithNormalizer(n *Normalizer) {
+	c.normalizer = n
+}
+
+//NormalizeCompany returns the canonical Vendor of company without aliases.
+//The display name is the company name without legal suffix and, when it is all upper case,
+//in title case except for words of up to three letters (acronyms such as IBM or AT&T).
+func NormalizeCompany(company string) Vendor {
+	words := strings.Fields(company)
+	tokens := make([][]string, len(words))
+
+	for i, w := range words {
+		tokens[i] = tokenize(w)
+	}
+
+	// Strip the legal suffixes from the end, keeping at least one word.
+	for end := len(words); end > 1; {
+		var tail []string
+		for _, t := range tokens[:end] {
+			tail = append(tail, t...)
+		}
+
+		n := legalSuffixLen(tail)
+		if n == 0 {
+			break
+		}
+
+		// Drop the words covered by the suffix; a word partly covered ("Co.,Ltd." after "Foo") is dropped whole.
+		for n > 0 && end > 1 {
+			n -= len(tokens[end-1])
+			end--
+		}
+
+		words, tokens = words[:end], tokens[:end]
+	}
+
+	var key []string
+	for _, t := range tokens {
+		key = append(key, t...)
+	}
+
+	if len(key) == 0 {
+		return Vendor{}
+	}
+
+	return Vendor{Key: strings.Join(key, " "), Name: displayName(words)}
+}
+
+// tokenize splits a word on punctuation into lower case letters and digits runs.
+func tokenize(word string) []string {
+	return strings.FieldsFunc(strings.ToLower(word), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// legalSuffixLen returns the number of tokens of the longest legal suffix ending tokens.
+func legalSuffixLen(tokens []string) int {
+	best := 0
+
+	for _, s := range legalSuffixes {
+		if len(s) <= best || len(s) >= len(tokens) {
+			continue
+		}
+
+		tail := tokens[len(tokens)-len(s):]
+
+		match := true
+		for i := range s {
+			if tail[i] != s[i] {
+				match = false
+				break
+			}
+		}
+
+		if match {
+			best = len(s)
+		}
+	}
+
+	return best
+}
+
+func displayName(words []string) string {
+	name := strings.TrimRight(strings.Join(words, " "), " ,.;:-&/")
+
+	if strings.ToUpper(name) != name || strings.ToLower(name) == name {
+		return name
+	}
+
+	out := strings.Fields(name)
+	for i, w := range out {
+		if letters(w) > 3 {
+			out[i] = titleCase(w)
+		}
+	}
+
+	return strings.Join(out, " ")
+}
+
+func letters(w string) int {
+	n := 0
+	for _, r := range w {
+		if unicode.IsLetter(r) {
+			n++
+		}
+	}
+
+	return n
+}
+
+func titleCase(w string) string {
+	r := []rune(strings.ToLower(w))
+	for i := range r {
+		if unicode.IsLetter(r[i]) {
+			r[i] = unicode.ToUpper(r[i])
+			break
+		}
+	}
+
+	return string(r)
+}
@@ -0,0 +1,91 @@
+package maclookup
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeCompany(t *testing.T) {
+	tests := []struct {
+		company string
+		want    Vendor
+	}{
+		{"Apple, Inc.", Vendor{Key: "apple", Name: "Apple"}},
+		{"APPLE INC", Vendor{Key: "apple", Name: "Apple"}},
+		{"  Apple   Inc ", Vendor{Key: "apple", Name: "Apple"}},
+		{"XEROX CORPORATION", Vendor{Key: "xerox", Name: "Xerox"}},
+		{"IBM Corp", Vendor{Key: "ibm", Name: "IBM"}},
+		{"HUAWEI TECHNOLOGIES CO.,LTD", Vendor{Key: "huawei technologies", Name: "Huawei Technologies"}},
+		{"Hon Hai Precision Ind. Co.,Ltd.", Vendor{Key: "hon hai precision ind", Name: "Hon Hai Precision Ind"}},
+		{"Shenzhen Foo Co., Ltd.", Vendor{Key: "shenzhen foo", Name: "Shenzhen Foo"}},
+		{"Siemens AG", Vendor{Key: "siemens", Name: "Siemens"}},
+		{"Robert Bosch GmbH", Vendor{Key: "robert bosch", Name: "Robert Bosch"}},
+		{"Philips Lighting B.V.", Vendor{Key: "philips lighting", Name: "Philips Lighting"}},
+		{"AT&T", Vendor{Key: "at t", Name: "AT&T"}},
+		{"Limited", Vendor{Key: "limited", Name: "Limited"}},
+		{"Inc.", Vendor{Key: "inc", Name: "Inc"}},
+		{"", Vendor{}},
+		{" .,", Vendor{}},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, NormalizeCompany(tt.company), tt.company)
+	}
+}
+
+func TestNormalizer_aliases(t *testing.T) {
+	n := NewNormalizer()
+	n.AddAlias("Hewlett Packard", "HP")
+
+	assert.Nil(t, n.LoadAliases(strings.NewReader("# variant,canonical\nCisco-Linksys LLC, Linksys\n\n\"Cisco Systems, Inc\",Cisco\n")))
+
+	assert.Equal(t, Vendor{Key: "hp", Name: "HP"}, n.Normalize("HEWLETT PACKARD"))
+	assert.Equal(t, Vendor{Key: "hp", Name: "HP"}, n.Normalize("HP Inc."))
+	assert.Equal(t, Vendor{Key: "linksys", Name: "Linksys"}, n.Normalize("Cisco-Linksys, LLC"))
+	assert.Equal(t, Vendor{Key: "cisco", Name: "Cisco"}, n.Normalize("CISCO SYSTEMS, INC."))
+	assert.Equal(t, Vendor{Key: "xerox", Name: "Xerox"}, n.Normalize("XEROX CORPORATION"))
+	assert.Equal(t, Vendor{}, n.Normalize(""))
+
+	var nilNormalizer *Normalizer
+	assert.Equal(t, Vendor{Key: "xerox", Name: "Xerox"}, nilNormalizer.Normalize("XEROX CORPORATION"))
+
+	assert.NotNil(t, n.LoadAliases(strings.NewReader("one,two,three\n")))
+	assert.NotNil(t, n.LoadAliases(strings.NewReader("only\n")))
+}
+
+func TestClient_WithNormalizer(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, companyNameSuffix) {
+			fmt.Fprint(w, "XEROX CORPORATION")
+			return
+		}
+
+		fmt.Fprintln(w, `{"success":true,"found":true,"macPrefix":"000000","company":"XEROX CORPORATION"}`)
+	}))
+	defer ts.Close()
+
+	client := New()
+	client.WithPrefixURI(ts.URL)
+
+	resp, err := client.Lookup("000000")
+	assert.Nil(t, err)
+	assert.Equal(t, Vendor{}, resp.Vendor)
+
+	n := NewNormalizer()
+	n.AddAlias("Xerox", "Xerox Holdings")
+	client.WithNormalizer(n)
+
+	resp, err = client.Lookup("000000")
+	assert.Nil(t, err)
+	assert.Equal(t, "XEROX CORPORATION", resp.Company)
+	assert.Equal(t, Vendor{Key: "xerox holdings", Name: "Xerox Holdings"}, resp.Vendor)
+
+	name, err := client.CompanyName("000000")
+	assert.Nil(t, err)
+	assert.Equal(t, Vendor{Key: "xerox holdings", Name: "Xerox Holdings"}, name.Vendor)
+}
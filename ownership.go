@@ -0,0 +1,263 @@
+package maclookup
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/logocomune/maclookup-go/internal/macaddr"
+)
+
+//OwnershipVersion is the version of the vendor hierarchy file format.
+const OwnershipVersion = 1
+
+//LevelUltimate selects the last organization of an ownership chain in RollUp.
+const LevelUltimate = -1
+
+//Organization is an entry of a vendor hierarchy. Companies are the MACInfo.Company values
+//owned by the organization, compared by their normalized key; Prefixes are MAC prefixes in hex
+//(e.g. "00000C" or "70B3D5F2F") owned by the organization whatever their Company.
+//Parent is the Name of the parent organization, empty for an ultimate parent.
+type Organization struct {
+	Name      string   `json:"name"`
+	Parent    string   `json:"parent,omitempty"`
+	Companies []string `json:"companies,omitempty"`
+	Prefixes  []string `json:"prefixes,omitempty"`
+}
+
+//OwnerGroup is a group of RollUp.
+type OwnerGroup struct {
+	Owner Vendor
+	Infos []MACInfo
+}
+
+// ownershipFile is the vendor hierarchy file: {"version":1,"organizations":[...]}.
+type ownershipFile struct {
+	Version       int            `json:"version"`
+	Organizations []Organization `json:"organizations"`
+}
+
+type prefixOwner struct {
+	prefix string
+	org    string
+}
+
+//Ownership resolves company names and MAC prefixes to their chain of parent organizations.
+//Its organization tree is fixed by NewOwnership, so Chain and RollUp can be called from several goroutines.
+type Ownership struct {
+	orgs      map[string]Organization
+	parents   map[string]string
+	companies map[string]string
+	prefixes  []prefixOwner
+}
+
+//NewOwnership creates an Ownership from orgs. It fails on duplicated organizations,
+//companies or prefixes, unknown parents and cycles.
+func NewOwnership(orgs ...Organization) (*Ownership, error) {
+	o := &Ownership{
+		orgs:      make(map[string]Organization),
+		parents:   make(map[string]string),
+		companies: make(map[string]string),
+	}
+
+	for _, org := range orgs {
+		key := NormalizeCompany(org.Name).Key
+		if key == "" {
+			return nil, fmt.Errorf("organization without name: %+v", org)
+		}
+
+		if _, ok := o.orgs[key]; ok {
+			return nil, fmt.Errorf("duplicated organization %q", org.Name)
+		}
+
+		o.orgs[key] = org
+	}
+
+	seenPrefixes := make(map[string]bool)
+
+	for key, org := range o.orgs {
+		if org.Parent != "" {
+			parent := NormalizeCompany(org.Parent).Key
+			if _, ok := o.orgs[parent]; !ok {
+				return nil, fmt.Errorf("organization %q: unknown parent %q", org.Name, org.Parent)
+			}
+
+			o.parents[key] = parent
+		}
+
+		for _, company := range append([]string{org.Name}, org.Companies...) {
+			ck := NormalizeCompany(company).Key
+			if owner, ok := o.companies[ck]; ok && owner != key {
+				return nil, fmt.Errorf("company %q owned by %q and %q", company, o.orgs[owner].Name, org.Name)
+			}
+
+			o.companies[ck] = key
+		}
+
+		for _, p := range org.Prefixes {
+			prefix := macaddr.Clean(p)
+			if !isHex(prefix) || len(prefix) < 6 || len(prefix) > 12 {
+				return nil, fmt.Errorf("organization %q: invalid prefix %q", org.Name, p)
+			}
+
+			if seenPrefixes[prefix] {
+				return nil, fmt.Errorf("duplicated prefix %q", p)
+			}
+
+			seenPrefixes[prefix] = true
+			o.prefixes = append(o.prefixes, prefixOwner{prefix: prefix, org: key})
+		}
+	}
+
+	// An organization name defined as the company of another organization wins for itself.
+	for key := range o.orgs {
+		o.companies[key] = key
+	}
+
+	for key := range o.orgs {
+		seen := map[string]bool{key: true}
+		for p, ok := o.parents[key]; ok; p, ok = o.parents[p] {
+			if seen[p] {
+				return nil, fmt.Errorf("ownership cycle at %q", o.orgs[key].Name)
+			}
+
+			seen[p] = true
+		}
+	}
+
+	// Longest prefixes first, so that the most specific one matches.
+	sort.Slice(o.prefixes, func(i, j int) bool {
+		if len(o.prefixes[i].prefix) != len(o.prefixes[j].prefix) {
+			return len(o.prefixes[i].prefix) > len(o.prefixes[j].prefix)
+		}
+
+		return o.prefixes[i].prefix < o.prefixes[j].prefix
+	})
+
+	return o, nil
+}
+
+//LoadOwnership reads a vendor hierarchy in JSON: {"version":1,"organizations":[{"name":...,"parent":...,"companies":[...],"prefixes":[...]}]}.
+func LoadOwnership(r io.Reader) (*Ownership, error) {
+	var f ownershipFile
+
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(&f); err != nil {
+		return nil, err
+	}
+
+	if f.Version < 0 || f.Version > OwnershipVersion {
+		return nil, fmt.Errorf("unsupported vendor hierarchy version %d", f.Version)
+	}
+
+	return NewOwnership(f.Organizations...)
+}
+
+//OpenOwnership reads a vendor hierarchy file written in the LoadOwnership format.
+func OpenOwnership(path string) (*Ownership, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return LoadOwnership(f)
+}
+
+//WithOwnership sets the Owners chain of every result.
+func (c *Client) WithOwnership(o *Ownership) {
+	c.ownership = o
+}
+
+//Chain returns the ownership chain of company (or of mac, a MAC address or prefix, when a prefix
+//of the hierarchy matches it): the owning organization, its parent, up to the ultimate parent.
+//A company missing from the hierarchy is its own ultimate parent. Chain returns nil for an empty company without matching prefix.
+func (o *Ownership) Chain(company, mac string) []Vendor {
+	key := o.owner(company, mac)
+	if key == "" {
+		if v := NormalizeCompany(company); v.Key != "" {
+			return []Vendor{v}
+		}
+
+		return nil
+	}
+
+	var chain []Vendor
+	for ok := true; ok; key, ok = o.parents[key] {
+		chain = append(chain, Vendor{Key: key, Name: o.orgs[key].Name})
+	}
+
+	return chain
+}
+
+//RollUp groups infos by the organization at level of their chain: 0 for the owning organization,
+//1 for its parent and so on; chains shorter than level, and LevelUltimate, use the ultimate parent.
+//Infos without company are not grouped. Groups are sorted by decreasing size, then by key.
+func (o *Ownership) RollUp(infos []MACInfo, level int) []OwnerGroup {
+	index := make(map[string]int)
+
+	var groups []OwnerGroup
+
+	for _, info := range infos {
+		chain := o.Chain(info.Company, info.MacPrefix)
+		if len(chain) == 0 {
+			continue
+		}
+
+		owner := chain[len(chain)-1]
+		if level >= 0 && level < len(chain) {
+			owner = chain[level]
+		}
+
+		i, ok := index[owner.Key]
+		if !ok {
+			i = len(groups)
+			index[owner.Key] = i
+			groups = append(groups, OwnerGroup{Owner: owner})
+		}
+
+		groups[i].Infos = append(groups[i].Infos, info)
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		if len(groups[i].Infos) != len(groups[j].Infos) {
+			return len(groups[i].Infos) > len(groups[j].Infos)
+		}
+
+		return groups[i].Owner.Key < groups[j].Owner.Key
+	})
+
+	return groups
+}
+
+// owner returns the key of the organization owning mac or company, empty if none.
+func (o *Ownership) owner(company, mac string) string {
+	if o == nil {
+		return ""
+	}
+
+	if m := macaddr.Clean(mac); m != "" {
+		for _, p := range o.prefixes {
+			if strings.HasPrefix(m, p.prefix) {
+				return p.org
+			}
+		}
+	}
+
+	return o.companies[NormalizeCompany(company).Key]
+}
+
+func isHex(s string) bool {
+	for _, r := range s {
+		if !strings.ContainsRune("0123456789ABCDEF", r) {
+			return false
+		}
+	}
+
+	return true
+}
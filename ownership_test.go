@@ -0,0 +1,140 @@
+package maclookup
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testHierarchy = `{
+	"version": 1,
+	"organizations": [
+		{"name": "Cisco", "companies": ["Cisco Systems, Inc"]},
+		{"name": "Linksys", "parent": "Cisco", "companies": ["Cisco-Linksys, LLC"]},
+		{"name": "Meraki", "parent": "Cisco", "companies": ["Cisco Meraki"]},
+		{"name": "Cisco SPVTG", "parent": "Cisco", "prefixes": ["00:0F:21", "70B3D5F2F"]},
+		{"name": "HPE", "companies": ["Hewlett Packard Enterprise"]},
+		{"name": "Aruba", "parent": "HPE", "companies": ["Aruba, a Hewlett Packard Enterprise Company"]}
+	]
+}`
+
+func loadTestHierarchy(t *testing.T) *Ownership {
+	o, err := LoadOwnership(strings.NewReader(testHierarchy))
+	assert.Nil(t, err)
+
+	return o
+}
+
+func TestOwnership_Chain(t *testing.T) {
+	o := loadTestHierarchy(t)
+
+	cisco := Vendor{Key: "cisco", Name: "Cisco"}
+
+	assert.Equal(t, []Vendor{cisco}, o.Chain("Cisco Systems, Inc.", ""))
+	assert.Equal(t, []Vendor{{Key: "linksys", Name: "Linksys"}, cisco}, o.Chain("CISCO-LINKSYS LLC", "000C41"))
+	assert.Equal(t, []Vendor{{Key: "meraki", Name: "Meraki"}, cisco}, o.Chain("Meraki", ""))
+	assert.Equal(t, []Vendor{{Key: "cisco spvtg", Name: "Cisco SPVTG"}, cisco}, o.Chain("Scientific-Atlanta, Inc.", "00-0F-21-12-34-56"))
+	assert.Equal(t, []Vendor{{Key: "cisco spvtg", Name: "Cisco SPVTG"}, cisco}, o.Chain("", "70B3D5F2F"))
+	assert.Equal(t, []Vendor{{Key: "aruba", Name: "Aruba"}, {Key: "hpe", Name: "HPE"}}, o.Chain("Aruba, a Hewlett Packard Enterprise Company", ""))
+	assert.Equal(t, []Vendor{{Key: "xerox", Name: "Xerox"}}, o.Chain("XEROX CORPORATION", "000000"))
+	assert.Nil(t, o.Chain("", "000000"))
+
+	var nilOwnership *Ownership
+	assert.Equal(t, []Vendor{{Key: "xerox", Name: "Xerox"}}, nilOwnership.Chain("XEROX CORPORATION", ""))
+}
+
+func TestOwnership_RollUp(t *testing.T) {
+	o := loadTestHierarchy(t)
+
+	infos := []MACInfo{
+		{MacPrefix: "000C41", Company: "Cisco-Linksys, LLC"},
+		{MacPrefix: "000F21", Company: "Scientific-Atlanta, Inc."},
+		{MacPrefix: "00000C", Company: "Cisco Systems, Inc"},
+		{MacPrefix: "000B86", Company: "Aruba, a Hewlett Packard Enterprise Company"},
+		{MacPrefix: "000000", Company: "XEROX CORPORATION"},
+		{MacPrefix: "020000"},
+	}
+
+	groups := o.RollUp(infos, LevelUltimate)
+	assert.Len(t, groups, 3)
+	assert.Equal(t, "cisco", groups[0].Owner.Key)
+	assert.Len(t, groups[0].Infos, 3)
+	assert.Equal(t, "hpe", groups[1].Owner.Key)
+	assert.Equal(t, "xerox", groups[2].Owner.Key)
+
+	groups = o.RollUp(infos, 1)
+	assert.Equal(t, "cisco", groups[0].Owner.Key)
+	assert.Len(t, groups[0].Infos, 3)
+
+	groups = o.RollUp(infos, 0)
+	assert.Len(t, groups, 5)
+	assert.Equal(t, []string{"aruba", "cisco", "cisco spvtg", "linksys", "xerox"}, ownerKeys(groups))
+}
+
+func ownerKeys(groups []OwnerGroup) []string {
+	var keys []string
+	for _, g := range groups {
+		keys = append(keys, g.Owner.Key)
+	}
+
+	return keys
+}
+
+func TestNewOwnership_errors(t *testing.T) {
+	tests := [][]Organization{
+		{{Name: ""}},
+		{{Name: "Cisco"}, {Name: "CISCO, Inc."}},
+		{{Name: "Linksys", Parent: "Cisco"}},
+		{{Name: "A", Parent: "B"}, {Name: "B", Parent: "A"}},
+		{{Name: "A", Parent: "A"}},
+		{{Name: "A", Companies: []string{"X"}}, {Name: "B", Companies: []string{"X Ltd"}}},
+		{{Name: "A", Prefixes: []string{"00000G"}}},
+		{{Name: "A", Prefixes: []string{"00000"}}},
+		{{Name: "A", Prefixes: []string{"000000"}}, {Name: "B", Prefixes: []string{"00:00:00"}}},
+	}
+
+	for _, orgs := range tests {
+		_, err := NewOwnership(orgs...)
+		assert.NotNil(t, err, "%+v", orgs)
+	}
+
+	_, err := LoadOwnership(strings.NewReader(`{"version":2,"organizations":[]}`))
+	assert.NotNil(t, err)
+
+	_, err = LoadOwnership(strings.NewReader(`{"version":1,"orgs":[]}`))
+	assert.NotNil(t, err)
+
+	_, err = OpenOwnership("testdata/missing.json")
+	assert.NotNil(t, err)
+}
+
+func TestClient_WithOwnership(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, companyNameSuffix) {
+			fmt.Fprint(w, "Cisco-Linksys, LLC")
+			return
+		}
+
+		fmt.Fprintln(w, `{"success":true,"found":true,"macPrefix":"000C41","company":"Cisco-Linksys, LLC"}`)
+	}))
+	defer ts.Close()
+
+	client := New()
+	client.WithPrefixURI(ts.URL)
+	client.WithOwnership(loadTestHierarchy(t))
+
+	want := []Vendor{{Key: "linksys", Name: "Linksys"}, {Key: "cisco", Name: "Cisco"}}
+
+	resp, err := client.Lookup("00:0C:41:11:22:33")
+	assert.Nil(t, err)
+	assert.Equal(t, want, resp.Owners)
+	assert.Equal(t, Vendor{}, resp.Vendor)
+
+	name, err := client.CompanyName("00:0C:41:11:22:33")
+	assert.Nil(t, err)
+	assert.Equal(t, want, name.Owners)
+}
@@ -0,0 +1,5 @@
+// Package pcap reads classic pcap and pcapng captures and builds a MAC address
+// inventory that can be resolved to vendors through the MACLookup API.
+//
+// Supported link types are Ethernet, raw 802.11 and 802.11 with radiotap headers.
+package pcap
@@ -0,0 +1,192 @@
+package pcap
+
+import (
+	"encoding/binary"
+	"net"
+)
+
+const (
+	etherTypeIPv4 = 0x0800
+	etherTypeARP  = 0x0806
+	etherTypeVLAN = 0x8100
+	etherTypeQinQ = 0x88a8
+	etherTypeIPv6 = 0x86dd
+
+	dot11TypeManagement = 0
+	dot11TypeControl    = 1
+	dot11TypeData       = 2
+)
+
+//Frame holds the addresses decoded from a captured packet.
+//Src and Dst are nil when the frame does not carry them (e.g. 802.11 ACKs have no transmitter address).
+type Frame struct {
+	Src   net.HardwareAddr
+	Dst   net.HardwareAddr
+	SrcIP net.IP
+	DstIP net.IP
+}
+
+//DecodeFrame extracts MAC and IP addresses from p.
+//It returns false when the link type is not supported or the frame is too short.
+func DecodeFrame(p Packet) (Frame, bool) {
+	switch p.LinkType {
+	case LinkTypeEthernet:
+		return decodeEthernet(p.Data)
+	case LinkTypeIEEE80211:
+		return decodeDot11(p.Data)
+	case LinkTypeRadiotap:
+		if len(p.Data) < 4 {
+			return Frame{}, false
+		}
+
+		hdrLen := int(binary.LittleEndian.Uint16(p.Data[2:4]))
+		if hdrLen > len(p.Data) {
+			return Frame{}, false
+		}
+
+		return decodeDot11(p.Data[hdrLen:])
+	}
+
+	return Frame{}, false
+}
+
+func decodeEthernet(data []byte) (Frame, bool) {
+	if len(data) < 14 {
+		return Frame{}, false
+	}
+
+	f := Frame{
+		Dst: hwAddr(data[0:6]),
+		Src: hwAddr(data[6:12]),
+	}
+
+	etherType := binary.BigEndian.Uint16(data[12:14])
+	payload := data[14:]
+
+	for (etherType == etherTypeVLAN || etherType == etherTypeQinQ) && len(payload) >= 4 {
+		etherType = binary.BigEndian.Uint16(payload[2:4])
+		payload = payload[4:]
+	}
+
+	decodeNetwork(&f, etherType, payload)
+
+	return f, true
+}
+
+func decodeDot11(data []byte) (Frame, bool) {
+	if len(data) < 10 {
+		return Frame{}, false
+	}
+
+	fc := data[0]
+	flags := data[1]
+	frameType := (fc >> 2) & 0x3
+	subType := fc >> 4
+	toDS := flags&0x01 != 0
+	fromDS := flags&0x02 != 0
+
+	var f Frame
+
+	switch frameType {
+	case dot11TypeControl:
+		f.Dst = hwAddr(data[4:10])
+		if len(data) >= 16 {
+			f.Src = hwAddr(data[10:16])
+		}
+
+		return f, true
+	case dot11TypeManagement:
+		if len(data) < 24 {
+			return Frame{}, false
+		}
+
+		f.Dst = hwAddr(data[4:10])
+		f.Src = hwAddr(data[10:16])
+
+		return f, true
+	case dot11TypeData:
+	default:
+		return Frame{}, false
+	}
+
+	hdrLen := 24
+	if toDS && fromDS {
+		hdrLen = 30
+	}
+
+	if subType&0x8 != 0 {
+		hdrLen += 2
+	}
+
+	if len(data) < hdrLen {
+		return Frame{}, false
+	}
+
+	addr1, addr2, addr3 := data[4:10], data[10:16], data[16:22]
+
+	switch {
+	case !toDS && !fromDS:
+		f.Dst, f.Src = hwAddr(addr1), hwAddr(addr2)
+	case toDS && !fromDS:
+		f.Dst, f.Src = hwAddr(addr3), hwAddr(addr2)
+	case !toDS && fromDS:
+		f.Dst, f.Src = hwAddr(addr1), hwAddr(addr3)
+	default:
+		f.Dst, f.Src = hwAddr(addr3), hwAddr(data[24:30])
+	}
+
+	// Encrypted payloads and null-data frames carry no readable LLC header.
+	payload := data[hdrLen:]
+	if flags&0x40 == 0 && len(payload) >= 8 && payload[0] == 0xaa && payload[1] == 0xaa && payload[2] == 0x03 {
+		decodeNetwork(&f, binary.BigEndian.Uint16(payload[6:8]), payload[8:])
+	}
+
+	return f, true
+}
+
+func decodeNetwork(f *Frame, etherType uint16, payload []byte) {
+	switch etherType {
+	case etherTypeIPv4:
+		if len(payload) >= 20 && payload[0]>>4 == 4 {
+			f.SrcIP = ipAddr(payload[12:16])
+			f.DstIP = ipAddr(payload[16:20])
+		}
+	case etherTypeIPv6:
+		if len(payload) >= 40 && payload[0]>>4 == 6 {
+			f.SrcIP = ipAddr(payload[8:24])
+			f.DstIP = ipAddr(payload[24:40])
+		}
+	case etherTypeARP:
+		// Only Ethernet/IPv4 ARP is decoded: htype 1, ptype 0x0800, hlen 6, plen 4.
+		if len(payload) >= 28 && payload[4] == 6 && payload[5] == 4 {
+			f.SrcIP = ipAddr(payload[14:18])
+			if !isZero(payload[18:24]) {
+				f.DstIP = ipAddr(payload[24:28])
+			}
+		}
+	}
+}
+
+func hwAddr(b []byte) net.HardwareAddr {
+	mac := make(net.HardwareAddr, len(b))
+	copy(mac, b)
+
+	return mac
+}
+
+func ipAddr(b []byte) net.IP {
+	ip := make(net.IP, len(b))
+	copy(ip, b)
+
+	return ip
+}
+
+func isZero(b []byte) bool {
+	for _, v := range b {
+		if v != 0 {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,145 @@
+package pcap
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"sort"
+	"time"
+)
+
+//Host is a unicast MAC address observed in a capture.
+type Host struct {
+	MAC            net.HardwareAddr
+	FirstSeen      time.Time
+	LastSeen       time.Time
+	SentFrames     int
+	ReceivedFrames int
+	IPs            []net.IP
+}
+
+//Frames returns the number of frames in which the host was either source or destination.
+func (h Host) Frames() int {
+	return h.SentFrames + h.ReceivedFrames
+}
+
+//Inventory collects the hosts seen in one or more captures.
+//Broadcast and multicast addresses are ignored since they do not identify a device.
+type Inventory struct {
+	hosts map[string]*Host
+}
+
+//NewInventory creates an empty inventory.
+func NewInventory() *Inventory {
+	return &Inventory{hosts: make(map[string]*Host)}
+}
+
+//ReadInventory reads every packet from a pcap or pcapng stream into a new inventory.
+func ReadInventory(r io.Reader) (*Inventory, error) {
+	inv := NewInventory()
+
+	if err := inv.ReadCapture(r); err != nil {
+		return nil, err
+	}
+
+	return inv, nil
+}
+
+//ReadCapture adds every packet of a pcap or pcapng stream to the inventory.
+func (inv *Inventory) ReadCapture(r io.Reader) error {
+	reader, err := NewReader(r)
+	if err != nil {
+		return err
+	}
+
+	for {
+		p, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		inv.Add(p)
+	}
+}
+
+//Add records the addresses of a single packet. Packets that cannot be decoded are ignored.
+func (inv *Inventory) Add(p Packet) {
+	f, ok := DecodeFrame(p)
+	if !ok {
+		return
+	}
+
+	if h := inv.host(f.Src, p.Timestamp); h != nil {
+		h.SentFrames++
+		h.addIP(f.SrcIP)
+	}
+
+	if h := inv.host(f.Dst, p.Timestamp); h != nil {
+		h.ReceivedFrames++
+		h.addIP(f.DstIP)
+	}
+}
+
+//Len returns the number of hosts in the inventory.
+func (inv *Inventory) Len() int {
+	return len(inv.hosts)
+}
+
+//Hosts returns the hosts sorted by MAC address.
+func (inv *Inventory) Hosts() []Host {
+	hosts := make([]Host, 0, len(inv.hosts))
+	for _, h := range inv.hosts {
+		hosts = append(hosts, *h)
+	}
+
+	sort.Slice(hosts, func(i, j int) bool {
+		return bytes.Compare(hosts[i].MAC, hosts[j].MAC) < 0
+	})
+
+	return hosts
+}
+
+func (inv *Inventory) host(mac net.HardwareAddr, ts time.Time) *Host {
+	if len(mac) != 6 || mac[0]&0x01 != 0 || isZero(mac) {
+		return nil
+	}
+
+	key := string(mac)
+
+	h, ok := inv.hosts[key]
+	if !ok {
+		h = &Host{MAC: mac, FirstSeen: ts, LastSeen: ts}
+		inv.hosts[key] = h
+	}
+
+	if !ts.IsZero() {
+		if h.FirstSeen.IsZero() || ts.Before(h.FirstSeen) {
+			h.FirstSeen = ts
+		}
+
+		if ts.After(h.LastSeen) {
+			h.LastSeen = ts
+		}
+	}
+
+	return h
+}
+
+func (h *Host) addIP(ip net.IP) {
+	if ip == nil || ip.IsUnspecified() || ip.IsMulticast() || ip.Equal(net.IPv4bcast) {
+		return
+	}
+
+	for _, known := range h.IPs {
+		if known.Equal(ip) {
+			return
+		}
+	}
+
+	h.IPs = append(h.IPs, ip)
+}
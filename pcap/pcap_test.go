@@ -0,0 +1,318 @@
+package pcap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/logocomune/maclookup-go"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	macA = net.HardwareAddr{0x00, 0x00, 0x00, 0x11, 0x22, 0x33}
+	macB = net.HardwareAddr{0xac, 0xde, 0x48, 0x00, 0x11, 0x22}
+	macC = net.HardwareAddr{0x02, 0x42, 0xac, 0x11, 0x00, 0x02}
+	bcst = net.HardwareAddr{0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+)
+
+func ethIPv4(dst, src net.HardwareAddr, srcIP, dstIP string) []byte {
+	var b bytes.Buffer
+
+	b.Write(dst)
+	b.Write(src)
+	b.Write([]byte{0x08, 0x00})
+
+	ip := make([]byte, 20)
+	ip[0] = 0x45
+	copy(ip[12:16], net.ParseIP(srcIP).To4())
+	copy(ip[16:20], net.ParseIP(dstIP).To4())
+	b.Write(ip)
+
+	return b.Bytes()
+}
+
+func radiotapData(bssid, src, dst net.HardwareAddr, srcIP, dstIP string) []byte {
+	var b bytes.Buffer
+
+	// radiotap header: version, pad, length (8), present flags
+	b.Write([]byte{0, 0, 8, 0, 0, 0, 0, 0})
+	// data frame, ToDS
+	b.Write([]byte{0x08, 0x01, 0, 0})
+	b.Write(bssid)
+	b.Write(src)
+	b.Write(dst)
+	b.Write([]byte{0, 0})
+	b.Write([]byte{0xaa, 0xaa, 0x03, 0, 0, 0, 0x08, 0x00})
+
+	ip := make([]byte, 20)
+	ip[0] = 0x45
+	copy(ip[12:16], net.ParseIP(srcIP).To4())
+	copy(ip[16:20], net.ParseIP(dstIP).To4())
+	b.Write(ip)
+
+	return b.Bytes()
+}
+
+func classicPcap(order binary.ByteOrder, link LinkType, ts []time.Time, frames ...[]byte) []byte {
+	var b bytes.Buffer
+
+	hdr := make([]byte, 24)
+	order.PutUint32(hdr[0:4], magicMicroseconds)
+	order.PutUint16(hdr[4:6], 2)
+	order.PutUint16(hdr[6:8], 4)
+	order.PutUint32(hdr[16:20], 65535)
+	order.PutUint32(hdr[20:24], uint32(link))
+	b.Write(hdr)
+
+	for i, f := range frames {
+		rec := make([]byte, 16)
+		order.PutUint32(rec[0:4], uint32(ts[i].Unix()))
+		order.PutUint32(rec[4:8], uint32(ts[i].Nanosecond()/1000))
+		order.PutUint32(rec[8:12], uint32(len(f)))
+		order.PutUint32(rec[12:16], uint32(len(f)))
+		b.Write(rec)
+		b.Write(f)
+	}
+
+	return b.Bytes()
+}
+
+func ngBlock(order binary.ByteOrder, blockType uint32, body []byte) []byte {
+	padded := make([]byte, pad4(len(body)))
+	copy(padded, body)
+
+	total := uint32(12 + len(padded))
+	out := make([]byte, 8, total)
+	order.PutUint32(out[0:4], blockType)
+	order.PutUint32(out[4:8], total)
+	out = append(out, padded...)
+
+	tail := make([]byte, 4)
+	order.PutUint32(tail, total)
+
+	return append(out, tail...)
+}
+
+func pcapNG(order binary.ByteOrder, link LinkType, tsresol byte, ts []uint64, frames ...[]byte) []byte {
+	var b bytes.Buffer
+
+	shb := make([]byte, 16)
+	order.PutUint32(shb[0:4], byteOrderMagic)
+	order.PutUint16(shb[4:6], 1)
+	binary.LittleEndian.PutUint64(shb[8:16], ^uint64(0))
+	b.Write(ngBlock(order, blockSectionHeader, shb))
+
+	idb := make([]byte, 20)
+	order.PutUint16(idb[0:2], uint16(link))
+	order.PutUint16(idb[8:10], optionInterfaceTSResl)
+	order.PutUint16(idb[10:12], 1)
+	idb[12] = tsresol
+	b.Write(ngBlock(order, blockInterface, idb))
+
+	for i, f := range frames {
+		epb := make([]byte, 20, 20+len(f))
+		order.PutUint32(epb[4:8], uint32(ts[i]>>32))
+		order.PutUint32(epb[8:12], uint32(ts[i]))
+		order.PutUint32(epb[12:16], uint32(len(f)))
+		order.PutUint32(epb[16:20], uint32(len(f)))
+		b.Write(ngBlock(order, blockEnhancedPacket, append(epb, f...)))
+	}
+
+	return b.Bytes()
+}
+
+func TestReader_Classic(t *testing.T) {
+	t0 := time.Date(2021, 11, 1, 10, 0, 0, 123000, time.UTC)
+	frame := ethIPv4(macB, macA, "10.0.0.1", "10.0.0.2")
+
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		r, err := NewReader(bytes.NewReader(classicPcap(order, LinkTypeEthernet, []time.Time{t0}, frame)))
+		assert.Nil(t, err)
+
+		p, err := r.Next()
+		assert.Nil(t, err)
+		assert.Equal(t, LinkTypeEthernet, p.LinkType)
+		assert.Equal(t, t0, p.Timestamp)
+		assert.Equal(t, frame, p.Data)
+		assert.Equal(t, len(frame), p.Length)
+
+		_, err = r.Next()
+		assert.True(t, errors.Is(err, io.EOF))
+	}
+}
+
+func TestReader_NG(t *testing.T) {
+	frame := ethIPv4(macB, macA, "10.0.0.1", "10.0.0.2")
+
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		// nanosecond resolution
+		r, err := NewReader(bytes.NewReader(pcapNG(order, LinkTypeEthernet, 9, []uint64{1635760800000000123}, frame)))
+		assert.Nil(t, err)
+
+		p, err := r.Next()
+		assert.Nil(t, err)
+		assert.Equal(t, time.Unix(1635760800, 123).UTC(), p.Timestamp)
+		assert.Equal(t, frame, p.Data)
+
+		_, err = r.Next()
+		assert.True(t, errors.Is(err, io.EOF))
+	}
+}
+
+func TestReader_UnknownFormat(t *testing.T) {
+	_, err := NewReader(bytes.NewReader([]byte("not a capture file")))
+	assert.True(t, errors.Is(err, ErrUnknownFormat))
+}
+
+func TestReader_Truncated(t *testing.T) {
+	data := classicPcap(binary.LittleEndian, LinkTypeEthernet, []time.Time{time.Now()}, ethIPv4(macB, macA, "10.0.0.1", "10.0.0.2"))
+
+	r, err := NewReader(bytes.NewReader(data[:len(data)-5]))
+	assert.Nil(t, err)
+
+	_, err = r.Next()
+	assert.NotNil(t, err)
+	assert.False(t, errors.Is(err, io.EOF))
+}
+
+func TestDecodeFrame_Radiotap(t *testing.T) {
+	f, ok := DecodeFrame(Packet{LinkType: LinkTypeRadiotap, Data: radiotapData(macC, macA, macB, "192.168.1.10", "192.168.1.20")})
+
+	assert.True(t, ok)
+	assert.Equal(t, macA, f.Src)
+	assert.Equal(t, macB, f.Dst)
+	assert.Equal(t, "192.168.1.10", f.SrcIP.String())
+	assert.Equal(t, "192.168.1.20", f.DstIP.String())
+}
+
+func TestDecodeFrame_VLAN(t *testing.T) {
+	frame := ethIPv4(macB, macA, "10.0.0.1", "10.0.0.2")
+	tagged := append(append(append([]byte{}, frame[:12]...), 0x81, 0x00, 0x00, 0x64), frame[12:]...)
+
+	f, ok := DecodeFrame(Packet{LinkType: LinkTypeEthernet, Data: tagged})
+	assert.True(t, ok)
+	assert.Equal(t, "10.0.0.1", f.SrcIP.String())
+}
+
+func TestDecodeFrame_Unsupported(t *testing.T) {
+	_, ok := DecodeFrame(Packet{LinkType: 228, Data: make([]byte, 64)})
+	assert.False(t, ok)
+
+	_, ok = DecodeFrame(Packet{LinkType: LinkTypeEthernet, Data: make([]byte, 10)})
+	assert.False(t, ok)
+}
+
+func TestInventory(t *testing.T) {
+	t0 := time.Date(2021, 11, 1, 10, 0, 0, 0, time.UTC)
+	t1 := t0.Add(time.Minute)
+	t2 := t0.Add(2 * time.Minute)
+
+	data := classicPcap(binary.LittleEndian, LinkTypeEthernet, []time.Time{t0, t1, t2},
+		ethIPv4(macB, macA, "10.0.0.1", "10.0.0.2"),
+		ethIPv4(macA, macB, "10.0.0.2", "10.0.0.1"),
+		ethIPv4(bcst, macA, "10.0.0.1", "255.255.255.255"),
+	)
+
+	inv, err := ReadInventory(bytes.NewReader(data))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, inv.Len())
+
+	hosts := inv.Hosts()
+	assert.Equal(t, macA, hosts[0].MAC)
+	assert.Equal(t, t0, hosts[0].FirstSeen)
+	assert.Equal(t, t2, hosts[0].LastSeen)
+	assert.Equal(t, 2, hosts[0].SentFrames)
+	assert.Equal(t, 1, hosts[0].ReceivedFrames)
+	assert.Equal(t, 3, hosts[0].Frames())
+	assert.Len(t, hosts[0].IPs, 1)
+	assert.Equal(t, "10.0.0.1", hosts[0].IPs[0].String())
+
+	assert.Equal(t, macB, hosts[1].MAC)
+	assert.Equal(t, t0, hosts[1].FirstSeen)
+	assert.Equal(t, t1, hosts[1].LastSeen)
+}
+
+type resolverFunc func(mac string) (maclookup.ResponseMACInfo, error)
+
+func (f resolverFunc) Lookup(mac string) (maclookup.ResponseMACInfo, error) {
+	return f(mac)
+}
+
+func TestInventory_Resolve(t *testing.T) {
+	now := time.Now()
+	data := classicPcap(binary.LittleEndian, LinkTypeEthernet, []time.Time{now, now},
+		ethIPv4(macB, macA, "10.0.0.1", "10.0.0.2"),
+		ethIPv4(macC, macA, "10.0.0.1", "172.17.0.2"),
+	)
+
+	inv, err := ReadInventory(bytes.NewReader(data))
+	assert.Nil(t, err)
+
+	calls := 0
+	report, err := inv.Resolve(resolverFunc(func(mac string) (maclookup.ResponseMACInfo, error) {
+		calls++
+
+		var r maclookup.ResponseMACInfo
+
+		switch mac {
+		case macA.String():
+			r.Found, r.Company = true, "XEROX CORPORATION"
+		case macC.String():
+			r.IsRand = true
+		}
+
+		return r, nil
+	}))
+
+	assert.Nil(t, err)
+	assert.Equal(t, 3, calls)
+	assert.Len(t, report.Hosts, 3)
+	assert.Equal(t, "XEROX CORPORATION", report.Hosts[0].Vendor)
+	assert.Equal(t, VendorRandom, report.Hosts[1].Vendor)
+	assert.Equal(t, VendorUnknown, report.Hosts[2].Vendor)
+	assert.Equal(t, VendorSummary{Vendor: "XEROX CORPORATION", Hosts: 1, Frames: 2}, report.Vendors[2])
+}
+
+func TestInventory_ResolveError(t *testing.T) {
+	data := classicPcap(binary.LittleEndian, LinkTypeEthernet, []time.Time{time.Now()}, ethIPv4(macB, macA, "10.0.0.1", "10.0.0.2"))
+
+	inv, err := ReadInventory(bytes.NewReader(data))
+	assert.Nil(t, err)
+
+	_, err = inv.Resolve(resolverFunc(func(mac string) (maclookup.ResponseMACInfo, error) {
+		return maclookup.ResponseMACInfo{}, &maclookup.RateLimitsExceeded{}
+	}))
+
+	var e *maclookup.RateLimitsExceeded
+
+	assert.True(t, errors.As(err, &e))
+}
+
+func TestHostReport_JSON(t *testing.T) {
+	report := HostReport{
+		Host:    Host{MAC: net.HardwareAddr{0, 0, 0, 0x11, 0x22, 0x33}, SentFrames: 3},
+		MACInfo: maclookup.MACInfo{Found: true, MacPrefix: "000000", Company: "XEROX CORPORATION"},
+		Vendor:  "XEROX CORPORATION",
+	}
+
+	data, err := json.Marshal(report)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"SentFrames":3`)
+	assert.Contains(t, string(data), `"MAC":"00:00:00:11:22:33"`)
+	assert.Contains(t, string(data), `"macPrefix":"000000"`)
+	assert.Contains(t, string(data), `"Vendor":"XEROX CORPORATION"`)
+
+	var decoded HostReport
+
+	assert.Nil(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, report.SentFrames, decoded.SentFrames)
+	assert.Equal(t, report.MAC, decoded.MAC)
+	assert.Equal(t, report.MACInfo, decoded.MACInfo)
+	assert.Equal(t, report.Vendor, decoded.Vendor)
+}
@@ -0,0 +1,384 @@
+package pcap
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	magicMicroseconds        = 0xa1b2c3d4
+	magicNanoseconds         = 0xa1b23c4d
+	magicMicrosecondsSwapped = 0xd4c3b2a1
+	magicNanosecondsSwapped  = 0x4d3cb2a1
+
+	blockSectionHeader    = 0x0a0d0d0a
+	blockInterface        = 0x00000001
+	blockPacketObsolete   = 0x00000002
+	blockSimplePacket     = 0x00000003
+	blockEnhancedPacket   = 0x00000006
+	byteOrderMagic        = 0x1a2b3c4d
+	optionEndOfOpt        = 0
+	optionInterfaceTSResl = 9
+
+	maxRecordSize = 16 << 20
+)
+
+//ErrUnknownFormat is returned when the input is neither a pcap nor a pcapng file.
+var ErrUnknownFormat = errors.New("pcap: unknown capture file format")
+
+//LinkType is the data link type of the captured frames.
+type LinkType uint32
+
+//Link types supported by the frame decoder.
+const (
+	LinkTypeEthernet  LinkType = 1
+	LinkTypeIEEE80211 LinkType = 105
+	LinkTypeRadiotap  LinkType = 127
+)
+
+//Packet is a single frame read from a capture file.
+type Packet struct {
+	Timestamp time.Time
+	LinkType  LinkType
+	Length    int
+	Data      []byte
+}
+
+//Reader reads packets from a classic pcap or a pcapng stream.
+type Reader struct {
+	r    *bufio.Reader
+	next func() (Packet, error)
+
+	// classic pcap state
+	order    binary.ByteOrder
+	nanos    bool
+	linkType LinkType
+
+	// pcapng state
+	interfaces []ngInterface
+}
+
+type ngInterface struct {
+	linkType LinkType
+	snapLen  uint32
+	unitsSec uint64
+}
+
+//NewReader detects the capture format of r and returns a Reader for it.
+func NewReader(r io.Reader) (*Reader, error) {
+	br := bufio.NewReader(r)
+
+	head, err := br.Peek(4)
+	if err != nil {
+		return nil, ErrUnknownFormat
+	}
+
+	reader := &Reader{r: br}
+
+	switch binary.LittleEndian.Uint32(head) {
+	case magicMicroseconds, magicMicrosecondsSwapped, magicNanoseconds, magicNanosecondsSwapped:
+		if err := reader.readFileHeader(); err != nil {
+			return nil, err
+		}
+
+		reader.next = reader.nextClassic
+	case blockSectionHeader:
+		reader.next = reader.nextNG
+	default:
+		return nil, ErrUnknownFormat
+	}
+
+	return reader, nil
+}
+
+//Next returns the next packet in the capture. It returns io.EOF when no packets are left.
+func (r *Reader) Next() (Packet, error) {
+	return r.next()
+}
+
+func (r *Reader) readFileHeader() error {
+	var hdr [24]byte
+	if _, err := io.ReadFull(r.r, hdr[:]); err != nil {
+		return fmt.Errorf("pcap: reading file header: %w", err)
+	}
+
+	switch binary.LittleEndian.Uint32(hdr[0:4]) {
+	case magicMicroseconds:
+		r.order = binary.LittleEndian
+	case magicNanoseconds:
+		r.order, r.nanos = binary.LittleEndian, true
+	case magicMicrosecondsSwapped:
+		r.order = binary.BigEndian
+	case magicNanosecondsSwapped:
+		r.order, r.nanos = binary.BigEndian, true
+	}
+
+	r.linkType = LinkType(r.order.Uint32(hdr[20:24]) & 0x0fffffff)
+
+	return nil
+}
+
+func (r *Reader) nextClassic() (Packet, error) {
+	var hdr [16]byte
+	if _, err := io.ReadFull(r.r, hdr[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return Packet{}, fmt.Errorf("pcap: truncated record header: %w", err)
+		}
+
+		return Packet{}, err
+	}
+
+	sec := int64(r.order.Uint32(hdr[0:4]))
+	frac := int64(r.order.Uint32(hdr[4:8]))
+	capLen := r.order.Uint32(hdr[8:12])
+	origLen := r.order.Uint32(hdr[12:16])
+
+	if capLen > maxRecordSize {
+		return Packet{}, fmt.Errorf("pcap: record length %d exceeds limit", capLen)
+	}
+
+	data := make([]byte, capLen)
+	if _, err := io.ReadFull(r.r, data); err != nil {
+		return Packet{}, fmt.Errorf("pcap: truncated record: %w", err)
+	}
+
+	if !r.nanos {
+		frac *= int64(time.Microsecond)
+	}
+
+	return Packet{
+		Timestamp: time.Unix(sec, frac).UTC(),
+		LinkType:  r.linkType,
+		Length:    int(origLen),
+		Data:      data,
+	}, nil
+}
+
+func (r *Reader) nextNG() (Packet, error) {
+	for {
+		blockType, body, err := r.readBlock()
+		if err != nil {
+			return Packet{}, err
+		}
+
+		switch blockType {
+		case blockSectionHeader:
+			r.interfaces = r.interfaces[:0]
+		case blockInterface:
+			if err := r.addInterface(body); err != nil {
+				return Packet{}, err
+			}
+		case blockEnhancedPacket:
+			return r.enhancedPacket(body)
+		case blockSimplePacket:
+			return r.simplePacket(body)
+		case blockPacketObsolete:
+			return r.obsoletePacket(body)
+		}
+	}
+}
+
+// readBlock returns the type and body of the next pcapng block, switching
+// byte order whenever a new section header is found.
+func (r *Reader) readBlock() (uint32, []byte, error) {
+	var hdr [8]byte
+	if _, err := io.ReadFull(r.r, hdr[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return 0, nil, fmt.Errorf("pcapng: truncated block header: %w", err)
+		}
+
+		return 0, nil, err
+	}
+
+	if binary.LittleEndian.Uint32(hdr[0:4]) == blockSectionHeader {
+		bom, err := r.r.Peek(4)
+		if err != nil {
+			return 0, nil, fmt.Errorf("pcapng: truncated section header: %w", err)
+		}
+
+		switch {
+		case binary.LittleEndian.Uint32(bom) == byteOrderMagic:
+			r.order = binary.LittleEndian
+		case binary.BigEndian.Uint32(bom) == byteOrderMagic:
+			r.order = binary.BigEndian
+		default:
+			return 0, nil, errors.New("pcapng: invalid byte-order magic")
+		}
+	}
+
+	if r.order == nil {
+		return 0, nil, errors.New("pcapng: block before section header")
+	}
+
+	blockType := r.order.Uint32(hdr[0:4])
+	total := r.order.Uint32(hdr[4:8])
+
+	if total < 12 || total%4 != 0 || total > maxRecordSize {
+		return 0, nil, fmt.Errorf("pcapng: invalid block length %d", total)
+	}
+
+	buf := make([]byte, total-8)
+	if _, err := io.ReadFull(r.r, buf); err != nil {
+		return 0, nil, fmt.Errorf("pcapng: truncated block: %w", err)
+	}
+
+	return blockType, buf[:len(buf)-4], nil
+}
+
+func (r *Reader) addInterface(body []byte) error {
+	if len(body) < 8 {
+		return errors.New("pcapng: short interface description block")
+	}
+
+	iface := ngInterface{
+		linkType: LinkType(r.order.Uint16(body[0:2])),
+		snapLen:  r.order.Uint32(body[4:8]),
+		unitsSec: 1000000,
+	}
+
+	opts := body[8:]
+	for len(opts) >= 4 {
+		code := r.order.Uint16(opts[0:2])
+		length := int(r.order.Uint16(opts[2:4]))
+
+		if code == optionEndOfOpt || 4+length > len(opts) {
+			break
+		}
+
+		if code == optionInterfaceTSResl && length >= 1 {
+			iface.unitsSec = tsResolution(opts[4])
+		}
+
+		opts = opts[4+pad4(length):]
+	}
+
+	r.interfaces = append(r.interfaces, iface)
+
+	return nil
+}
+
+func (r *Reader) iface(id uint32) (ngInterface, error) {
+	if int(id) >= len(r.interfaces) {
+		return ngInterface{}, fmt.Errorf("pcapng: packet references unknown interface %d", id)
+	}
+
+	return r.interfaces[id], nil
+}
+
+func (r *Reader) enhancedPacket(body []byte) (Packet, error) {
+	if len(body) < 20 {
+		return Packet{}, errors.New("pcapng: short enhanced packet block")
+	}
+
+	iface, err := r.iface(r.order.Uint32(body[0:4]))
+	if err != nil {
+		return Packet{}, err
+	}
+
+	ts := uint64(r.order.Uint32(body[4:8]))<<32 | uint64(r.order.Uint32(body[8:12]))
+	capLen := r.order.Uint32(body[12:16])
+
+	if int(capLen) > len(body)-20 {
+		return Packet{}, errors.New("pcapng: enhanced packet exceeds block")
+	}
+
+	return Packet{
+		Timestamp: tsTime(ts, iface.unitsSec),
+		LinkType:  iface.linkType,
+		Length:    int(r.order.Uint32(body[16:20])),
+		Data:      body[20 : 20+capLen],
+	}, nil
+}
+
+func (r *Reader) obsoletePacket(body []byte) (Packet, error) {
+	if len(body) < 20 {
+		return Packet{}, errors.New("pcapng: short packet block")
+	}
+
+	iface, err := r.iface(uint32(r.order.Uint16(body[0:2])))
+	if err != nil {
+		return Packet{}, err
+	}
+
+	ts := uint64(r.order.Uint32(body[4:8]))<<32 | uint64(r.order.Uint32(body[8:12]))
+	capLen := r.order.Uint32(body[12:16])
+
+	if int(capLen) > len(body)-20 {
+		return Packet{}, errors.New("pcapng: packet exceeds block")
+	}
+
+	return Packet{
+		Timestamp: tsTime(ts, iface.unitsSec),
+		LinkType:  iface.linkType,
+		Length:    int(r.order.Uint32(body[16:20])),
+		Data:      body[20 : 20+capLen],
+	}, nil
+}
+
+// simplePacket decodes a simple packet block. Simple packets carry no
+// timestamp, so the returned Packet has a zero Timestamp.
+func (r *Reader) simplePacket(body []byte) (Packet, error) {
+	if len(body) < 4 {
+		return Packet{}, errors.New("pcapng: short simple packet block")
+	}
+
+	iface, err := r.iface(0)
+	if err != nil {
+		return Packet{}, err
+	}
+
+	origLen := r.order.Uint32(body[0:4])
+	capLen := uint32(len(body) - 4)
+
+	if origLen < capLen {
+		capLen = origLen
+	}
+
+	if iface.snapLen > 0 && iface.snapLen < capLen {
+		capLen = iface.snapLen
+	}
+
+	return Packet{
+		LinkType: iface.linkType,
+		Length:   int(origLen),
+		Data:     body[4 : 4+capLen],
+	}, nil
+}
+
+func tsResolution(v byte) uint64 {
+	exp := uint64(v & 0x7f)
+	base := uint64(10)
+
+	if v&0x80 != 0 {
+		base = 2
+	}
+
+	units := uint64(1)
+	for i := uint64(0); i < exp && units < 1<<60/base; i++ {
+		units *= base
+	}
+
+	return units
+}
+
+func tsTime(ts, unitsSec uint64) time.Time {
+	sec := ts / unitsSec
+	frac := ts % unitsSec
+
+	var nsec uint64
+	if unitsSec <= uint64(time.Second) {
+		nsec = frac * uint64(time.Second) / unitsSec
+	} else {
+		nsec = frac / (unitsSec / uint64(time.Second))
+	}
+
+	return time.Unix(int64(sec), int64(nsec)).UTC()
+}
+
+func pad4(n int) int {
+	return (n + 3) &^ 3
+}
@@ -0,0 +1,149 @@
+package pcap
+
+import (
+	"encoding/json"
+	"net"
+	"sort"
+
+	"github.com/logocomune/maclookup-go"
+)
+
+//Vendor labels used in a Report for hosts without a company name.
+const (
+	VendorUnknown = "(unknown)"
+	VendorPrivate = maclookup.VendorPrivate
+	VendorRandom  = "(random)"
+)
+
+//HostReport is a host enriched with the information returned by the API.
+type HostReport struct {
+	Host
+	maclookup.MACInfo
+	Vendor string
+}
+
+// The JSON encoding of HostReport flattens the host and the API fields in a single object, with MAC
+// as "aa:bb:cc:dd:ee:ff": the json.Marshaler promoted from maclookup.MACInfo would encode only the API fields.
+type (
+	hostFields    Host
+	macInfoFields maclookup.MACInfo
+)
+
+type hostReportJSON struct {
+	hostFields
+	MAC string
+	macInfoFields
+	Vendor string
+}
+
+//MarshalJSON implements json.Marshaler.
+func (h HostReport) MarshalJSON() ([]byte, error) {
+	return json.Marshal(hostReportJSON{hostFields(h.Host), h.MAC.String(), macInfoFields(h.MACInfo), h.Vendor})
+}
+
+//UnmarshalJSON implements json.Unmarshaler.
+func (h *HostReport) UnmarshalJSON(data []byte) error {
+	var v hostReportJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	host := Host(v.hostFields)
+	if v.MAC != "" {
+		mac, err := net.ParseMAC(v.MAC)
+		if err != nil {
+			return err
+		}
+
+		host.MAC = mac
+	}
+
+	*h = HostReport{host, maclookup.MACInfo(v.macInfoFields), v.Vendor}
+
+	return nil
+}
+
+//VendorSummary aggregates hosts and frames by vendor.
+type VendorSummary struct {
+	Vendor string
+	Hosts  int
+	Frames int
+}
+
+//Report is the vendor breakdown of an inventory.
+type Report struct {
+	Hosts   []HostReport
+	Vendors []VendorSummary
+}
+
+//Resolve looks up every host of the inventory and builds a Report.
+//Hosts with the same maclookup.Prefix share one lookup. If a lookup fails,
+//the hosts resolved before it and their vendor summary are returned with the error.
+func (inv *Inventory) Resolve(r maclookup.MACInfoResolver) (Report, error) {
+	var report Report
+
+	cache := make(map[string]maclookup.MACInfo)
+	vendors := make(map[string]*VendorSummary)
+
+	for _, h := range inv.Hosts() {
+		key := maclookup.Prefix(h.MAC.String())
+
+		info, ok := cache[key]
+		if !ok {
+			resp, err := r.Lookup(h.MAC.String())
+			if err != nil {
+				report.Vendors = sortVendors(vendors)
+				return report, err
+			}
+
+			info = resp.MACInfo
+			cache[key] = info
+		}
+
+		hr := HostReport{Host: h, MACInfo: info, Vendor: vendorLabel(info)}
+		report.Hosts = append(report.Hosts, hr)
+
+		v, ok := vendors[hr.Vendor]
+		if !ok {
+			v = &VendorSummary{Vendor: hr.Vendor}
+			vendors[hr.Vendor] = v
+		}
+
+		v.Hosts++
+		v.Frames += h.Frames()
+	}
+
+	report.Vendors = sortVendors(vendors)
+
+	return report, nil
+}
+
+func vendorLabel(info maclookup.MACInfo) string {
+	switch {
+	case info.IsPrivate:
+		return VendorPrivate
+	case info.Found && info.Company != "":
+		return info.Company
+	case info.IsRand:
+		return VendorRandom
+	}
+
+	return VendorUnknown
+}
+
+func sortVendors(vendors map[string]*VendorSummary) []VendorSummary {
+	list := make([]VendorSummary, 0, len(vendors))
+	for _, v := range vendors {
+		list = append(list, *v)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Hosts != list[j].Hosts {
+			return list[i].Hosts > list[j].Hosts
+		}
+
+		return list[i].Vendor < list[j].Vendor
+	})
+
+	return list
+}
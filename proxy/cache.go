@@ -0,0 +1,87 @@
+package proxy
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/logocomune/maclookup-go"
+)
+
+// cache is a size bounded LRU cache of lookups with a fixed TTL.
+type cache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+	entries    map[string]*list.Element
+	lru        *list.List
+}
+
+type cacheEntry struct {
+	key     string
+	info    maclookup.MACInfo
+	expires time.Time
+}
+
+func newCache(ttl time.Duration, maxEntries int) *cache {
+	return &cache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		now:        time.Now,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+func (c *cache) get(key string) (maclookup.MACInfo, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return maclookup.MACInfo{}, false
+	}
+
+	e := el.Value.(*cacheEntry)
+	if !c.now().Before(e.expires) {
+		c.lru.Remove(el)
+		delete(c.entries, key)
+
+		return maclookup.MACInfo{}, false
+	}
+
+	c.lru.MoveToFront(el)
+
+	return e.info, true
+}
+
+func (c *cache) set(key string, info maclookup.MACInfo) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expires := c.now().Add(c.ttl)
+
+	if el, ok := c.entries[key]; ok {
+		e := el.Value.(*cacheEntry)
+		e.info, e.expires = info, expires
+		c.lru.MoveToFront(el)
+
+		return
+	}
+
+	c.entries[key] = c.lru.PushFront(&cacheEntry{key: key, info: info, expires: expires})
+
+	for c.maxEntries > 0 && c.lru.Len() > c.maxEntries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+func (c *cache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.lru.Len()
+}
@@ -0,0 +1,5 @@
+// Package proxy implements a caching reverse proxy for the MACLookup API v2.
+//
+// Hosts point their client at the proxy with WithPrefixURI; the proxy answers from a
+// shared cache and forwards misses upstream with a single API key.
+package proxy
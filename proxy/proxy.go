@@ -0,0 +1,170 @@
+package proxy
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/logocomune/maclookup-go"
+	"github.com/logocomune/maclookup-go/internal/apiv2"
+)
+
+const (
+	defaultTTL        = 24 * time.Hour
+	defaultMaxEntries = 100000
+)
+
+//Proxy is an http.Handler serving the MACLookup API v2 MAC endpoints from a shared cache.
+//Misses are resolved upstream with Lookup, so a single upstream request answers both
+//the MAC lookup and the company name endpoints. API keys sent by callers are ignored:
+//the upstream client carries the central key.
+type Proxy struct {
+	upstream maclookup.MACInfoResolver
+	cache    *cache
+
+	mu        sync.Mutex
+	inflight  map[string]*call
+	rateLimit maclookup.RateLimit
+
+	hits   int64
+	misses int64
+}
+
+type call struct {
+	done chan struct{}
+	info maclookup.MACInfo
+	err  error
+}
+
+//Stats are the cache counters of a Proxy.
+type Stats struct {
+	Hits    int64
+	Misses  int64
+	Entries int
+}
+
+//New creates a Proxy resolving misses with upstream, usually a *maclookup.Client
+//configured with the central API key.
+//Entries are cached for 24 hours, up to 100000 entries.
+func New(upstream maclookup.MACInfoResolver) *Proxy {
+	return &Proxy{
+		upstream: upstream,
+		cache:    newCache(defaultTTL, defaultMaxEntries),
+		inflight: make(map[string]*call),
+	}
+}
+
+//WithCache changes the TTL and the maximum number of cached entries (0 means unbounded).
+//Already cached entries are dropped.
+func (p *Proxy) WithCache(ttl time.Duration, maxEntries int) {
+	p.cache = newCache(ttl, maxEntries)
+}
+
+//Stats returns the cache counters.
+func (p *Proxy) Stats() Stats {
+	return Stats{
+		Hits:    atomic.LoadInt64(&p.hits),
+		Misses:  atomic.LoadInt64(&p.misses),
+		Entries: p.cache.len(),
+	}
+}
+
+//ServeHTTP implements http.Handler.
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	req, ok := apiv2.ParseRequest(r)
+	if !ok || (r.Method != http.MethodGet && r.Method != http.MethodHead) {
+		http.NotFound(w, r)
+		return
+	}
+
+	mac := apiv2.CleanMAC(req.MAC)
+	if e := apiv2.ValidateMAC(mac); e != nil {
+		apiv2.WriteError(w, req.CompanyName, http.StatusBadRequest, *e)
+		return
+	}
+
+	info, err := p.resolve(mac)
+	p.writeRateLimit(w.Header())
+
+	if err != nil {
+		writeUpstreamError(w, req.CompanyName, err)
+		return
+	}
+
+	apiv2.WriteMACInfo(w, req.CompanyName, mac, info)
+}
+
+// resolve answers from the cache, or performs a single upstream lookup shared by concurrent callers.
+func (p *Proxy) resolve(mac string) (maclookup.MACInfo, error) {
+	if info, ok := p.cache.get(mac); ok {
+		atomic.AddInt64(&p.hits, 1)
+		return info, nil
+	}
+
+	atomic.AddInt64(&p.misses, 1)
+
+	p.mu.Lock()
+	if c, ok := p.inflight[mac]; ok {
+		p.mu.Unlock()
+		<-c.done
+
+		return c.info, c.err
+	}
+
+	c := &call{done: make(chan struct{})}
+	p.inflight[mac] = c
+	p.mu.Unlock()
+
+	resp, err := p.upstream.Lookup(mac)
+	c.info, c.err = resp.MACInfo, err
+
+	if err == nil {
+		p.cache.set(mac, resp.MACInfo)
+	}
+
+	p.mu.Lock()
+	if resp.RateLimit.Limit >= 0 && !resp.RateLimit.Reset.IsZero() {
+		p.rateLimit = resp.RateLimit
+	}
+
+	delete(p.inflight, mac)
+	p.mu.Unlock()
+	close(c.done)
+
+	return c.info, c.err
+}
+
+// writeRateLimit re-emits the last rate limit seen upstream.
+func (p *Proxy) writeRateLimit(h http.Header) {
+	p.mu.Lock()
+	rl := p.rateLimit
+	p.mu.Unlock()
+
+	if rl.Reset.IsZero() {
+		return
+	}
+
+	h.Set(apiv2.HeaderRateLimit, strconv.FormatInt(rl.Limit, 10))
+	h.Set(apiv2.HeaderRateRemaining, strconv.FormatInt(rl.Remaining, 10))
+	h.Set(apiv2.HeaderRateReset, strconv.FormatInt(rl.Reset.Unix(), 10))
+}
+
+func writeUpstreamError(w http.ResponseWriter, companyName bool, err error) {
+	var (
+		rateErr    *maclookup.RateLimitsExceeded
+		requestErr *maclookup.BadAPIRequest
+	)
+
+	switch {
+	case errors.As(err, &rateErr):
+		apiv2.WriteError(w, companyName, http.StatusTooManyRequests, apiv2.TooManyRequests())
+	case errors.As(err, &requestErr):
+		apiv2.WriteError(w, companyName, http.StatusBadRequest, apiv2.ErrorResponse{Error: requestErr.Error(), MoreInfo: apiv2.MoreInfoDocumentation})
+	default:
+		// Upstream key, transport and response errors are not the caller's fault.
+		apiv2.WriteError(w, companyName, http.StatusBadGateway, apiv2.ErrorResponse{Error: "Bad Gateway", ErrorCode: http.StatusBadGateway, MoreInfo: apiv2.MoreInfoDocumentation})
+	}
+}
@@ -0,0 +1,154 @@
+package proxy
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/logocomune/maclookup-go"
+	"github.com/logocomune/maclookup-go/maclookuptest"
+	"github.com/stretchr/testify/assert"
+)
+
+func newProxy(t *testing.T) (*maclookuptest.Server, *Proxy, *maclookup.Client) {
+	upstream := maclookuptest.NewServer()
+	upstream.WithAPIKeys("CENTRAL")
+	upstream.WithRateLimit(100, time.Minute)
+	t.Cleanup(upstream.Close)
+
+	upstreamClient := upstream.NewClient()
+	upstreamClient.WithAPIKey("CENTRAL")
+
+	p := New(upstreamClient)
+	ts := httptest.NewServer(p)
+	t.Cleanup(ts.Close)
+
+	client := maclookup.New()
+	client.WithPrefixURI(ts.URL)
+
+	return upstream, p, client
+}
+
+func TestProxy_Cache(t *testing.T) {
+	upstream, p, client := newProxy(t)
+
+	r, err := client.Lookup("000000")
+	assert.Nil(t, err)
+	assert.Equal(t, "XEROX CORPORATION", r.Company)
+	assert.Equal(t, "MA-L", r.BlockType)
+	assert.Equal(t, int64(100), r.Limit)
+	assert.Equal(t, int64(99), r.Remaining)
+
+	name, err := client.CompanyName("00:00:00")
+	assert.Nil(t, err)
+	assert.Equal(t, "XEROX CORPORATION", name.Company)
+	assert.Equal(t, int64(99), name.Remaining)
+
+	name, err = client.CompanyName("ACDE48")
+	assert.Nil(t, err)
+	assert.True(t, name.IsPrivate)
+
+	r, err = client.Lookup("02:42:AC")
+	assert.Nil(t, err)
+	assert.False(t, r.Found)
+	assert.True(t, r.IsRand)
+
+	name, err = client.CompanyName("02:42:AC")
+	assert.Nil(t, err)
+	assert.False(t, name.Found)
+
+	assert.Equal(t, 3, upstream.Requests())
+	assert.Equal(t, Stats{Hits: 2, Misses: 3, Entries: 3}, p.Stats())
+}
+
+func TestProxy_BadRequest(t *testing.T) {
+	upstream, _, client := newProxy(t)
+
+	_, err := client.Lookup("0000")
+
+	var e *maclookup.BadAPIRequest
+
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, 0, upstream.Requests())
+}
+
+func TestProxy_UpstreamErrors(t *testing.T) {
+	upstream, _, client := newProxy(t)
+
+	upstream.FailNext(1, http.StatusInternalServerError)
+
+	_, err := client.Lookup("000000")
+
+	var httpErr *maclookup.HTTPClientError
+
+	assert.True(t, errors.As(err, &httpErr))
+
+	upstream.WithRateLimit(0, time.Minute)
+
+	_, err = client.CompanyName("000000")
+
+	var rateErr *maclookup.RateLimitsExceeded
+
+	assert.True(t, errors.As(err, &rateErr))
+
+	upstream.WithAPIKeys("OTHER")
+
+	_, err = client.Lookup("000000")
+	assert.True(t, errors.As(err, &httpErr))
+}
+
+func TestProxy_SingleFlight(t *testing.T) {
+	upstream, _, client := newProxy(t)
+	upstream.WithLatency(50 * time.Millisecond)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, err := client.CompanyName("000000")
+			assert.Nil(t, err)
+		}()
+	}
+
+	wg.Wait()
+	assert.Equal(t, 1, upstream.Requests())
+}
+
+func TestProxy_NotFound(t *testing.T) {
+	_, p, _ := newProxy(t)
+
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/macs/000000", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestCache(t *testing.T) {
+	now := time.Now()
+	c := newCache(time.Minute, 2)
+	c.now = func() time.Time { return now }
+
+	c.set("A", maclookup.MACInfo{Company: "A"})
+	c.set("B", maclookup.MACInfo{Company: "B"})
+
+	_, ok := c.get("A")
+	assert.True(t, ok)
+
+	c.set("C", maclookup.MACInfo{Company: "C"})
+
+	_, ok = c.get("B")
+	assert.False(t, ok)
+	assert.Equal(t, 2, c.len())
+
+	now = now.Add(time.Minute)
+
+	_, ok = c.get("A")
+	assert.False(t, ok)
+	assert.Equal(t, 1, c.len())
+}
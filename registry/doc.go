@@ -0,0 +1,6 @@
+// Package registry loads the IEEE MAC address registries (MA-L, MA-M, MA-S, IAB and CID CSV files)
+// and resolves MAC addresses offline into maclookup.MACInfo values.
+//
+// The CSV files are published at https://standards-oui.ieee.org/ (oui/oui.csv, oui28/mam.csv,
+// oui36/oui36.csv, iab/iab.csv and cid/cid.csv).
+package registry
@@ -0,0 +1,131 @@
+package registry
+
+import (
+	"sort"
+
+	"github.com/logocomune/maclookup-go"
+)
+
+//Count is the number of blocks and of addresses of a group of blocks.
+type Count struct {
+	Blocks    int    `json:"blocks"`
+	Addresses uint64 `json:"addresses"`
+}
+
+//CompanyCount is the Count of the blocks of a company, grouped by normalized company name.
+type CompanyCount struct {
+	Vendor maclookup.Vendor `json:"vendor"`
+	Count
+}
+
+//Stats are aggregate statistics of a set of blocks.
+type Stats struct {
+	Total       Count            `json:"total"`
+	Companies   int              `json:"companies"`
+	Private     Count            `json:"private"`
+	ByBlockType map[string]Count `json:"byBlockType"`
+	ByCountry   map[string]Count `json:"byCountry"`
+}
+
+//Select returns the blocks of the registry matching q, sorted by prefix. Company names are normalized by n,
+//which can be nil to use maclookup.NormalizeCompany. Unlike ReverseIndex.Find, an empty Company matches every block.
+func (r *Registry) Select(q Query, n *maclookup.Normalizer) []maclookup.MACInfo {
+	query := n.Normalize(q.Company).Key
+
+	var found []maclookup.MACInfo
+
+	for _, block := range r.Blocks() {
+		if query != "" && !q.matchKey(query, n.Normalize(block.Company).Key) {
+			continue
+		}
+
+		if q.accept(block) {
+			found = append(found, block)
+		}
+	}
+
+	return found
+}
+
+//Summarize returns the statistics of blocks. The addresses of a block are BlockSize+1.
+func Summarize(blocks []maclookup.MACInfo) Stats {
+	s := Stats{ByBlockType: make(map[string]Count), ByCountry: make(map[string]Count)}
+	companies := make(map[string]bool)
+
+	for _, block := range blocks {
+		n := addresses(block)
+
+		s.Total.add(n)
+		s.ByBlockType[block.BlockType] = s.ByBlockType[block.BlockType].plus(n)
+		s.ByCountry[block.Country] = s.ByCountry[block.Country].plus(n)
+
+		if block.IsPrivate {
+			s.Private.add(n)
+		}
+
+		if key := maclookup.NormalizeCompany(block.Company).Key; key != "" {
+			companies[key] = true
+		}
+	}
+
+	s.Companies = len(companies)
+
+	return s
+}
+
+//TopCompanies returns the n companies with the most addresses in blocks, then the most blocks.
+//n <= 0 returns every company.
+func TopCompanies(blocks []maclookup.MACInfo, n int) []CompanyCount {
+	index := make(map[string]int)
+
+	var top []CompanyCount
+
+	for _, block := range blocks {
+		v := maclookup.NormalizeCompany(block.Company)
+		if v.Key == "" {
+			continue
+		}
+
+		i, ok := index[v.Key]
+		if !ok {
+			i = len(top)
+			index[v.Key] = i
+			top = append(top, CompanyCount{Vendor: v})
+		}
+
+		top[i].add(addresses(block))
+	}
+
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Addresses != top[j].Addresses {
+			return top[i].Addresses > top[j].Addresses
+		}
+
+		if top[i].Blocks != top[j].Blocks {
+			return top[i].Blocks > top[j].Blocks
+		}
+
+		return top[i].Vendor.Key < top[j].Vendor.Key
+	})
+
+	if n > 0 && len(top) > n {
+		top = top[:n]
+	}
+
+	return top
+}
+
+func (c *Count) add(addresses uint64) {
+	c.Blocks++
+	c.Addresses += addresses
+}
+
+func (c Count) plus(addresses uint64) Count {
+	c.add(addresses)
+
+	return c
+}
+
+func addresses(block maclookup.MACInfo) uint64 {
+	return uint64(block.BlockSize) + 1
+}
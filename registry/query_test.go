@@ -0,0 +1,84 @@
+package registry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/logocomune/maclookup-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func loadQueryTestdata(t *testing.T) *Registry {
+	r := loadTestdata(t)
+	assert.Nil(t, r.LoadFile("testdata/dated.csv"))
+
+	return r
+}
+
+func prefixesOf(blocks []maclookup.MACInfo) []string {
+	var p []string
+	for _, b := range blocks {
+		p = append(p, b.MacPrefix)
+	}
+
+	return p
+}
+
+func TestLoad_updated(t *testing.T) {
+	r := loadQueryTestdata(t)
+
+	info, ok := r.Lookup("70B3D5002123")
+	assert.True(t, ok)
+	assert.Equal(t, "2021-06-15", info.Updated)
+	assert.Equal(t, "DE", info.Country)
+
+	info, _ = r.Lookup("000000")
+	assert.Equal(t, "", info.Updated)
+}
+
+func TestRegistry_Select(t *testing.T) {
+	r := loadQueryTestdata(t)
+
+	assert.Len(t, r.Select(Query{}, nil), 9)
+	assert.Equal(t, []string{"70B3D5002"}, prefixesOf(r.Select(Query{
+		BlockTypes:   []string{BlockTypeMAS},
+		UpdatedSince: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	}, nil)))
+	assert.Equal(t, []string{"70B3D5001", "F8E43B"}, prefixesOf(r.Select(Query{
+		UpdatedUntil: time.Date(2020, 1, 10, 0, 0, 0, 0, time.UTC),
+	}, nil)))
+	assert.Equal(t, []string{"000000", "002272", "70B3D5", "70B3D5001"}, prefixesOf(r.Select(Query{Countries: []string{"US"}}, nil)))
+	assert.Equal(t, []string{"70B3D5001", "70B3D5002", "70B3D5F2F", "F0ACD7A"}, prefixesOf(r.Select(Query{MaxBlockSize: 1 << 20}, nil)))
+	assert.Equal(t, []string{"F0ACD7A"}, prefixesOf(r.Select(Query{MinBlockSize: 1 << 16, MaxBlockSize: 1 << 20}, nil)))
+	assert.Equal(t, []string{"70B3D5001", "70B3D5002", "70B3D5F2F"}, prefixesOf(r.Select(Query{Company: "sensors", Match: MatchSubstring}, nil)))
+	assert.Equal(t, []string{"000000"}, prefixesOf(r.Select(Query{Company: "Xerox"}, nil)))
+
+	n := maclookup.NewNormalizer()
+	n.AddAlias("New Sensors GmbH", "Xerox")
+	assert.Equal(t, []string{"000000", "70B3D5002"}, prefixesOf(r.Select(Query{Company: "Xerox"}, n)))
+}
+
+func TestSummarize(t *testing.T) {
+	r := loadQueryTestdata(t)
+
+	s := Summarize(r.Select(Query{}, nil))
+	assert.Equal(t, 9, s.Total.Blocks)
+	assert.Equal(t, uint64(5*(1<<24)+(1<<20)+3*(1<<12)), s.Total.Addresses)
+	assert.Equal(t, 8, s.Companies)
+	assert.Equal(t, Count{Blocks: 1, Addresses: 1 << 24}, s.Private)
+	assert.Equal(t, Count{Blocks: 3, Addresses: 3 << 12}, s.ByBlockType[BlockTypeMAS])
+	assert.Equal(t, Count{Blocks: 4, Addresses: 3<<24 + 1<<12}, s.ByCountry["US"])
+}
+
+func TestTopCompanies(t *testing.T) {
+	r := loadQueryTestdata(t)
+	r.Add(maclookup.MACInfo{MacPrefix: "9C934E", BlockType: BlockTypeMAL, Company: "Xerox Corporation"})
+
+	top := TopCompanies(r.Select(Query{}, nil), 2)
+	assert.Len(t, top, 2)
+	assert.Equal(t, maclookup.Vendor{Key: "xerox", Name: "Xerox"}, top[0].Vendor)
+	assert.Equal(t, Count{Blocks: 2, Addresses: 2 << 24}, top[0].Count)
+	assert.Equal(t, "american micro fuel device", top[1].Vendor.Key)
+
+	assert.Len(t, TopCompanies(r.Select(Query{}, nil), 0), 8)
+}
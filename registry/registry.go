@@ -0,0 +1,249 @@
+package registry
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/logocomune/maclookup-go"
+	"github.com/logocomune/maclookup-go/internal/apiv2"
+)
+
+//Block types of the IEEE registries, as found in MACInfo.BlockType.
+const (
+	BlockTypeMAL = string(maclookup.BlockTypeMAL)
+	BlockTypeMAM = string(maclookup.BlockTypeMAM)
+	BlockTypeMAS = string(maclookup.BlockTypeMAS)
+	BlockTypeIAB = string(maclookup.BlockTypeIAB)
+	BlockTypeCID = string(maclookup.BlockTypeCID)
+)
+
+const privateOrganization = "Private"
+
+// prefixLen is the number of hex digits of the assignment of each registry.
+var prefixLen = map[string]int{
+	BlockTypeMAL: 6,
+	BlockTypeMAM: 7,
+	BlockTypeMAS: 9,
+	BlockTypeIAB: 9,
+	BlockTypeCID: 6,
+}
+
+//Registry is an in-memory copy of the IEEE MAC address registries
+//(oui.csv, mam.csv, oui36.csv, iab.csv and cid.csv).
+type Registry struct {
+	blocks []maclookup.MACInfo
+	index  map[string]int
+}
+
+//New creates an empty registry.
+func New() *Registry {
+	return &Registry{index: make(map[string]int)}
+}
+
+//LoadFiles creates a registry from IEEE registry CSV files.
+func LoadFiles(paths ...string) (*Registry, error) {
+	r := New()
+
+	for _, p := range paths {
+		if err := r.LoadFile(p); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+//LoadFile adds the assignments of an IEEE registry CSV file.
+func (r *Registry) LoadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := r.Load(f); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	return nil
+}
+
+//Load adds the assignments of an IEEE registry CSV stream
+//("Registry,Assignment,Organization Name,Organization Address").
+//The IEEE files carry no assignment date: an optional "Updated" column (YYYY-MM-DD) sets MACInfo.Updated.
+//An assignment already loaded is replaced.
+func (r *Registry) Load(rd io.Reader) error {
+	cr := csv.NewReader(rd)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		return err
+	}
+
+	if len(header) < 3 || !strings.EqualFold(strings.TrimSpace(strings.TrimPrefix(header[0], "\ufeff")), "Registry") {
+		return errors.New("registry: missing IEEE CSV header")
+	}
+
+	updated := -1
+
+	for i, h := range header {
+		if strings.EqualFold(strings.TrimSpace(h), "Updated") {
+			updated = i
+		}
+	}
+
+	for {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if len(rec) < 3 {
+			continue
+		}
+
+		block, ok := newBlock(rec)
+		if !ok {
+			continue
+		}
+
+		if updated >= 3 && updated < len(rec) {
+			block.Updated = strings.TrimSpace(rec[updated])
+		}
+
+		r.add(block)
+	}
+}
+
+//Add adds or replaces a block. MacPrefix, BlockType and Company are required;
+//the remaining fields are derived from them when empty.
+func (r *Registry) Add(block maclookup.MACInfo) {
+	block.Found = true
+	block.MacPrefix = apiv2.CleanMAC(block.MacPrefix)
+	fillBounds(&block)
+	r.add(block)
+}
+
+func (r *Registry) add(block maclookup.MACInfo) {
+	if i, ok := r.index[block.MacPrefix]; ok {
+		r.blocks[i] = block
+		return
+	}
+
+	r.index[block.MacPrefix] = len(r.blocks)
+	r.blocks = append(r.blocks, block)
+}
+
+//Len returns the number of blocks.
+func (r *Registry) Len() int {
+	return len(r.blocks)
+}
+
+//Blocks returns every block sorted by prefix.
+func (r *Registry) Blocks() []maclookup.MACInfo {
+	blocks := append([]maclookup.MACInfo(nil), r.blocks...)
+
+	sort.Slice(blocks, func(i, j int) bool {
+		return blocks[i].MacPrefix < blocks[j].MacPrefix
+	})
+
+	return blocks
+}
+
+//Lookup returns the most specific block containing mac.
+//mac can be a full address or a prefix of at least 6 hex digits, with or without separators.
+func (r *Registry) Lookup(mac string) (maclookup.MACInfo, bool) {
+	m := apiv2.CleanMAC(mac)
+
+	for _, n := range []int{9, 7, 6} {
+		if len(m) < n {
+			continue
+		}
+
+		if i, ok := r.index[m[:n]]; ok {
+			return r.blocks[i], true
+		}
+	}
+
+	return maclookup.MACInfo{}, false
+}
+
+func newBlock(rec []string) (maclookup.MACInfo, bool) {
+	blockType := strings.ToUpper(strings.TrimSpace(rec[0]))
+	prefix := apiv2.CleanMAC(rec[1])
+
+	if n, ok := prefixLen[blockType]; !ok || len(prefix) != n {
+		return maclookup.MACInfo{}, false
+	}
+
+	block := maclookup.MACInfo{
+		Found:     true,
+		MacPrefix: prefix,
+		BlockType: blockType,
+		Company:   strings.TrimSpace(rec[2]),
+	}
+
+	if len(rec) > 3 {
+		block.Address = strings.Join(strings.Fields(rec[3]), " ")
+		block.Country = countryFromAddress(block.Address)
+	}
+
+	if strings.EqualFold(block.Company, privateOrganization) {
+		block.Company = ""
+		block.IsPrivate = true
+	}
+
+	fillBounds(&block)
+
+	return block, true
+}
+
+// fillBounds sets BlockStart, BlockEnd and BlockSize from MacPrefix.
+// BlockSize is the number of addresses minus one, as reported by the API.
+func fillBounds(block *maclookup.MACInfo) {
+	free := 12 - len(block.MacPrefix)
+	if free < 0 {
+		return
+	}
+
+	if block.BlockStart == "" {
+		block.BlockStart = block.MacPrefix + strings.Repeat("0", free)
+	}
+
+	if block.BlockEnd == "" {
+		block.BlockEnd = block.MacPrefix + strings.Repeat("F", free)
+	}
+
+	if block.BlockSize == 0 {
+		block.BlockSize = 1<<(4*uint(free)) - 1
+	}
+}
+
+// countryFromAddress returns the last two letter upper case word of an IEEE address,
+// where the registry places the ISO 3166 country code (after the state, before the postal code).
+func countryFromAddress(address string) string {
+	fields := strings.Fields(address)
+
+	for i := len(fields) - 1; i >= 0; i-- {
+		f := strings.Trim(fields[i], ",.")
+		if len(f) == 2 && f[0] >= 'A' && f[0] <= 'Z' && f[1] >= 'A' && f[1] <= 'Z' {
+			return f
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,85 @@
+package registry
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/logocomune/maclookup-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func loadTestdata(t *testing.T) *Registry {
+	r, err := LoadFiles("testdata/oui.csv", "testdata/mam.csv", "testdata/oui36.csv")
+	assert.Nil(t, err)
+
+	return r
+}
+
+func TestLoadFiles(t *testing.T) {
+	r := loadTestdata(t)
+	assert.Equal(t, 6, r.Len())
+
+	_, err := LoadFiles("testdata/missing.csv")
+	assert.NotNil(t, err)
+}
+
+func TestRegistry_Lookup(t *testing.T) {
+	r := loadTestdata(t)
+
+	info, ok := r.Lookup("00:00:00:11:22:33")
+	assert.True(t, ok)
+	assert.Equal(t, maclookup.MACInfo{
+		Found:      true,
+		MacPrefix:  "000000",
+		Company:    "XEROX CORPORATION",
+		Address:    "M/S 105-50C WEBSTER NY US 14580",
+		Country:    "US",
+		BlockStart: "000000000000",
+		BlockEnd:   "000000FFFFFF",
+		BlockSize:  16777215,
+		BlockType:  "MA-L",
+	}, info)
+
+	info, ok = r.Lookup("70B3D5F2F123")
+	assert.True(t, ok)
+	assert.Equal(t, "Example Sensors, Ltd.", info.Company)
+	assert.Equal(t, "GB", info.Country)
+	assert.Equal(t, "70B3D5F2FFFF", info.BlockEnd)
+	assert.Equal(t, 4095, info.BlockSize)
+
+	info, ok = r.Lookup("70-B3-D5-00-00-01")
+	assert.True(t, ok)
+	assert.Equal(t, "IEEE Registration Authority", info.Company)
+
+	info, ok = r.Lookup("F0:AC:D7:A1")
+	assert.True(t, ok)
+	assert.Equal(t, "MA-M", info.BlockType)
+	assert.Equal(t, "DE", info.Country)
+
+	info, ok = r.Lookup("ACDE48")
+	assert.True(t, ok)
+	assert.True(t, info.IsPrivate)
+	assert.Empty(t, info.Company)
+
+	_, ok = r.Lookup("010000")
+	assert.False(t, ok)
+
+	_, ok = r.Lookup("0000")
+	assert.False(t, ok)
+}
+
+func TestRegistry_Load(t *testing.T) {
+	r := New()
+
+	err := r.Load(strings.NewReader("\ufeffRegistry,Assignment,Organization Name,Organization Address\nMA-L,00:00:00,XEROX,\nMA-L,0000,TOO SHORT,\nXX,000001,UNKNOWN,\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, r.Len())
+
+	err = r.Load(strings.NewReader("a,b,c\n"))
+	assert.NotNil(t, err)
+
+	r.Add(maclookup.MACInfo{MacPrefix: "00:00:00", BlockType: BlockTypeMAL, Company: "REPLACED"})
+	assert.Equal(t, 1, r.Len())
+	assert.Equal(t, "REPLACED", r.Blocks()[0].Company)
+	assert.Equal(t, "000000FFFFFF", r.Blocks()[0].BlockEnd)
+}
@@ -0,0 +1,353 @@
+package registry
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/logocomune/maclookup-go"
+)
+
+//MatchMode is how a Query compares company names, after normalization (see maclookup.NormalizeCompany).
+type MatchMode int
+
+const (
+	//MatchExact matches the companies with the same canonical key as the query.
+	MatchExact MatchMode = iota
+	//MatchPrefix matches the companies whose key starts with the key of the query.
+	MatchPrefix
+	//MatchSubstring matches the companies whose key contains the key of the query.
+	MatchSubstring
+	//MatchFuzzy matches the companies whose key, or a run of as many words of it as the query has,
+	//is at least Query.MinScore similar to the key of the query (by edit distance).
+	MatchFuzzy
+)
+
+//DefaultMinScore is the similarity required by MatchFuzzy when Query.MinScore is zero.
+const DefaultMinScore = 0.8
+
+//Query selects registry blocks. ReverseIndex.Find requires Company; the other fields are optional filters:
+//UpdatedSince and UpdatedUntil are inclusive days and exclude blocks without Updated,
+//MinBlockSize and MaxBlockSize bound MACInfo.BlockSize when not zero.
+type Query struct {
+	Company      string
+	Match        MatchMode
+	MinScore     float64
+	BlockTypes   []string
+	Countries    []string
+	UpdatedSince time.Time
+	UpdatedUntil time.Time
+	MinBlockSize int
+	MaxBlockSize int
+}
+
+//ReverseIndex finds the blocks assigned to a company. It is read only and safe for concurrent use.
+type ReverseIndex struct {
+	keys       []string
+	blocks     map[string][]maclookup.MACInfo
+	normalizer *maclookup.Normalizer
+}
+
+//NewReverseIndex indexes the blocks of r by company. Company names are normalized by n,
+//which can be nil to use maclookup.NormalizeCompany. Blocks added to r later are not indexed.
+func NewReverseIndex(r *Registry, n *maclookup.Normalizer) *ReverseIndex {
+	x := &ReverseIndex{blocks: make(map[string][]maclookup.MACInfo), normalizer: n}
+
+	for _, block := range r.Blocks() {
+		key := n.Normalize(block.Company).Key
+		if key == "" {
+			continue
+		}
+
+		if _, ok := x.blocks[key]; !ok {
+			x.keys = append(x.keys, key)
+		}
+
+		x.blocks[key] = append(x.blocks[key], block)
+	}
+
+	sort.Strings(x.keys)
+
+	return x
+}
+
+//Find returns the blocks matching q, sorted by prefix.
+func (x *ReverseIndex) Find(q Query) []maclookup.MACInfo {
+	var found []maclookup.MACInfo
+
+	for _, key := range x.match(q) {
+		for _, block := range x.blocks[key] {
+			if q.accept(block) {
+				found = append(found, block)
+			}
+		}
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].MacPrefix < found[j].MacPrefix
+	})
+
+	return found
+}
+
+//Companies returns the distinct company names of the blocks matching q, sorted.
+func (x *ReverseIndex) Companies(q Query) []string {
+	seen := make(map[string]bool)
+
+	var companies []string
+
+	for _, block := range x.Find(q) {
+		if !seen[block.Company] {
+			seen[block.Company] = true
+			companies = append(companies, block.Company)
+		}
+	}
+
+	sort.Strings(companies)
+
+	return companies
+}
+
+// match returns the company keys matching q.
+func (x *ReverseIndex) match(q Query) []string {
+	query := x.normalizer.Normalize(q.Company).Key
+	if query == "" {
+		return nil
+	}
+
+	if q.Match == MatchExact {
+		if _, ok := x.blocks[query]; ok {
+			return []string{query}
+		}
+
+		return nil
+	}
+
+	var keys []string
+
+	for _, key := range x.keys {
+		if q.matchKey(query, key) {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
+// matchKey reports whether the company key matches the normalized query.
+func (q Query) matchKey(query, key string) bool {
+	switch q.Match {
+	case MatchExact:
+		return key == query
+	case MatchPrefix:
+		return strings.HasPrefix(key, query)
+	case MatchSubstring:
+		return strings.Contains(key, query)
+	case MatchFuzzy:
+		minScore := q.MinScore
+		if minScore == 0 {
+			minScore = DefaultMinScore
+		}
+
+		return fuzzyScore(query, key) >= minScore
+	}
+
+	return false
+}
+
+// accept applies the filters of q other than Company.
+func (q Query) accept(block maclookup.MACInfo) bool {
+	if !acceptAny(q.BlockTypes, block.BlockType) || !acceptAny(q.Countries, block.Country) {
+		return false
+	}
+
+	if q.MinBlockSize != 0 && block.BlockSize < q.MinBlockSize || q.MaxBlockSize != 0 && block.BlockSize > q.MaxBlockSize {
+		return false
+	}
+
+	if q.UpdatedSince.IsZero() && q.UpdatedUntil.IsZero() {
+		return true
+	}
+
+	updated, err := block.UpdatedAt()
+	if err != nil || updated.IsZero() {
+		return false
+	}
+
+	day := func(t time.Time) string { return t.Format(maclookup.UpdatedFormat) }
+
+	return (q.UpdatedSince.IsZero() || day(updated) >= day(q.UpdatedSince)) &&
+		(q.UpdatedUntil.IsZero() || day(updated) <= day(q.UpdatedUntil))
+}
+
+func acceptAny(values []string, v string) bool {
+	if len(values) == 0 {
+		return true
+	}
+
+	for _, value := range values {
+		if strings.EqualFold(strings.TrimSpace(value), v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fuzzyScore returns the best similarity between query and key or a run of words of key of the same length as query.
+func fuzzyScore(query, key string) float64 {
+	best := similarity(query, key)
+
+	qn := len(strings.Fields(query))
+	words := strings.Fields(key)
+
+	for i := 0; i+qn <= len(words); i++ {
+		if s := similarity(query, strings.Join(words[i:i+qn], " ")); s > best {
+			best = s
+		}
+	}
+
+	return best
+}
+
+// similarity is 1 minus the edit distance of a and b divided by the length of the longest.
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+
+	if longest == 0 {
+		return 1
+	}
+
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+
+		prev, cur = cur, prev
+	}
+
+	return prev[len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+
+	if c < a {
+		a = c
+	}
+
+	return a
+}
+
+//RuleFormat is the syntax of the match rules written by WriteRules.
+type RuleFormat string
+
+//Match rule formats.
+const (
+	//RuleRange writes the first and last address of each block: "00:00:00:00:00:00-00:00:00:FF:FF:FF".
+	RuleRange RuleFormat = "range"
+	//RuleMask writes each block as an address and a mask, as ebtables: "00:00:00:00:00:00/FF:FF:FF:00:00:00".
+	RuleMask RuleFormat = "mask"
+	//RuleNftables writes each block as an nftables expression: "ether saddr & ff:ff:ff:00:00:00 == 00:00:00:00:00:00".
+	RuleNftables RuleFormat = "nftables"
+)
+
+//WritePrefixList writes one block per line as its first address and prefix length in bits
+//("70:B3:D5:F2:F0:00/36"), followed by a comment with the company name.
+func WritePrefixList(w io.Writer, blocks []maclookup.MACInfo) error {
+	for _, block := range blocks {
+		start, _, prefixLen, err := block.Bounds()
+		if err != nil {
+			return err
+		}
+
+		if _, err := fmt.Fprintf(w, "%s/%d\t# %s\n", formatMAC(start), prefixLen, block.Company); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//WriteRules writes one match rule per block in format.
+func WriteRules(w io.Writer, blocks []maclookup.MACInfo, format RuleFormat) error {
+	for _, block := range blocks {
+		start, end, prefixLen, err := block.Bounds()
+		if err != nil {
+			return err
+		}
+
+		var rule string
+
+		switch format {
+		case RuleRange:
+			rule = formatMAC(start) + "-" + formatMAC(end)
+		case RuleMask:
+			rule = formatMAC(start) + "/" + formatMAC(mask(prefixLen))
+		case RuleNftables:
+			rule = fmt.Sprintf("ether saddr & %s == %s", strings.ToLower(formatMAC(mask(prefixLen))), strings.ToLower(formatMAC(start)))
+		default:
+			return fmt.Errorf("registry: unknown rule format %q", format)
+		}
+
+		if _, err := fmt.Fprintln(w, rule); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func mask(prefixLen int) []byte {
+	m := make([]byte, 6)
+
+	for i := range m {
+		switch {
+		case prefixLen >= 8:
+			m[i] = 0xff
+			prefixLen -= 8
+		case prefixLen > 0:
+			m[i] = byte(0xff << uint(8-prefixLen))
+			prefixLen = 0
+		}
+	}
+
+	return m
+}
+
+// formatMAC formats a MAC address in upper case hex separated by colons.
+func formatMAC(mac []byte) string {
+	parts := make([]string, len(mac))
+	for i, b := range mac {
+		parts[i] = fmt.Sprintf("%02X", b)
+	}
+
+	return strings.Join(parts, ":")
+}
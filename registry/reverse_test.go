@@ -0,0 +1,87 @@
+package registry
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/logocomune/maclookup-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func reverseTestdata(t *testing.T, n *maclookup.Normalizer) *ReverseIndex {
+	r := loadTestdata(t)
+	r.Add(maclookup.MACInfo{MacPrefix: "9C:93:4E", BlockType: BlockTypeMAL, Company: "Xerox Corporation", Country: "US"})
+	r.Add(maclookup.MACInfo{MacPrefix: "F0ACD7B", BlockType: BlockTypeMAM, Company: "XEROX LIMITED", Country: "GB"})
+	r.Add(maclookup.MACInfo{MacPrefix: "00AA00", BlockType: BlockTypeMAL, Company: "Xerographic Labs", Country: "US"})
+
+	return NewReverseIndex(r, n)
+}
+
+func TestReverseIndex_Find(t *testing.T) {
+	x := reverseTestdata(t, nil)
+
+	prefixes := func(q Query) []string {
+		var p []string
+		for _, b := range x.Find(q) {
+			p = append(p, b.MacPrefix)
+		}
+
+		return p
+	}
+
+	assert.Equal(t, []string{"000000", "9C934E", "F0ACD7B"}, prefixes(Query{Company: "xerox corp."}))
+	assert.Equal(t, []string{"000000", "00AA00", "9C934E", "F0ACD7B"}, prefixes(Query{Company: "Xero", Match: MatchPrefix}))
+	assert.Equal(t, []string{"70B3D5F2F"}, prefixes(Query{Company: "sensors", Match: MatchSubstring}))
+	assert.Equal(t, []string{"000000", "9C934E", "F0ACD7B"}, prefixes(Query{Company: "Xerx", Match: MatchFuzzy}))
+	assert.Equal(t, []string{"002272"}, prefixes(Query{Company: "american micro fuel devise", Match: MatchFuzzy}))
+	assert.Equal(t, []string{"F0ACD7A"}, prefixes(Query{Company: "Cameras", Match: MatchFuzzy}))
+	assert.Nil(t, prefixes(Query{Company: "Camels", Match: MatchFuzzy, MinScore: 0.9}))
+
+	assert.Equal(t, []string{"000000", "9C934E"}, prefixes(Query{Company: "Xerox", BlockTypes: []string{"ma-l"}, Countries: []string{"us"}}))
+	assert.Equal(t, []string{"F0ACD7B"}, prefixes(Query{Company: "Xerox", BlockTypes: []string{BlockTypeMAM, BlockTypeMAS}}))
+
+	assert.Nil(t, prefixes(Query{Company: "Private"}))
+	assert.Nil(t, prefixes(Query{Company: ""}))
+	assert.Nil(t, prefixes(Query{Company: "Acme"}))
+
+	assert.Equal(t, []string{"XEROX CORPORATION", "XEROX LIMITED", "Xerox Corporation"}, x.Companies(Query{Company: "Xerox"}))
+}
+
+func TestReverseIndex_normalizer(t *testing.T) {
+	n := maclookup.NewNormalizer()
+	n.AddAlias("Xerographic Labs", "Xerox")
+
+	x := reverseTestdata(t, n)
+	assert.Len(t, x.Find(Query{Company: "Xerox"}), 4)
+}
+
+func TestWritePrefixList(t *testing.T) {
+	x := reverseTestdata(t, nil)
+	blocks := x.Find(Query{Company: "Example", Match: MatchPrefix})
+
+	var buf bytes.Buffer
+	assert.Nil(t, WritePrefixList(&buf, blocks))
+	assert.Equal(t, "70:B3:D5:F2:F0:00/36\t# Example Sensors, Ltd.\nF0:AC:D7:A0:00:00/28\t# Example Cameras GmbH\n", buf.String())
+}
+
+func TestWriteRules(t *testing.T) {
+	x := reverseTestdata(t, nil)
+	blocks := x.Find(Query{Company: "Example", Match: MatchPrefix})
+
+	tests := []struct {
+		format RuleFormat
+		want   string
+	}{
+		{RuleRange, "70:B3:D5:F2:F0:00-70:B3:D5:F2:FF:FF\nF0:AC:D7:A0:00:00-F0:AC:D7:AF:FF:FF\n"},
+		{RuleMask, "70:B3:D5:F2:F0:00/FF:FF:FF:FF:F0:00\nF0:AC:D7:A0:00:00/FF:FF:FF:F0:00:00\n"},
+		{RuleNftables, "ether saddr & ff:ff:ff:ff:f0:00 == 70:b3:d5:f2:f0:00\nether saddr & ff:ff:ff:f0:00:00 == f0:ac:d7:a0:00:00\n"},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		assert.Nil(t, WriteRules(&buf, blocks, tt.format))
+		assert.Equal(t, tt.want, buf.String(), tt.format)
+	}
+
+	assert.NotNil(t, WriteRules(&bytes.Buffer{}, blocks, "iptables"))
+}
@@ -0,0 +1,8 @@
+// Package replay provides a record/replay http.RoundTripper for deterministic tests of code using the client.
+//
+// Record once against the real API, then replay the fixture offline:
+//
+//	t, err := replay.New("testdata/lookup.json", replay.ModeRecord) // or replay.ModeReplay in CI
+//	client := maclookup.New()
+//	client.WithHTTPClient(t.Client())
+package replay
@@ -0,0 +1,244 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+//Mode selects whether a Transport records or replays interactions.
+type Mode int
+
+//Transport modes.
+const (
+	ModeReplay Mode = iota
+	ModeRecord
+)
+
+const (
+	apiKeyParam = "apiKey"
+	redacted    = "REDACTED"
+)
+
+// recordedHeaders are the response headers saved in fixtures.
+var recordedHeaders = []string{
+	"Content-Type",
+	"X-RateLimit-Limit",
+	"X-RateLimit-Remaining",
+	"X-RateLimit-Reset",
+}
+
+//Interaction is a recorded request/response pair.
+type Interaction struct {
+	Method  string              `json:"method"`
+	URL     string              `json:"url"`
+	Status  int                 `json:"status"`
+	Headers map[string][]string `json:"headers,omitempty"`
+	Body    string              `json:"body"`
+}
+
+//Fixture is the content of a fixture file.
+type Fixture struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+//UnmatchedRequestError is returned in replay mode when no recorded interaction matches a request.
+type UnmatchedRequestError struct {
+	Method string
+	URL    string
+}
+
+func (e *UnmatchedRequestError) Error() string {
+	return fmt.Sprintf("replay: no recorded interaction for %s %s", e.Method, e.URL)
+}
+
+//Transport is an http.RoundTripper that records interactions to a fixture file or replays them.
+//The apiKey query parameter is redacted both in the fixture and when matching requests,
+//so fixtures recorded with a real key replay with any key.
+type Transport struct {
+	mode Mode
+	path string
+	next http.RoundTripper
+
+	mu      sync.Mutex
+	fixture Fixture
+	used    []bool
+}
+
+//New creates a Transport for the fixture file at path.
+//In replay mode the fixture is loaded immediately; in record mode it is (re)written after every request,
+//and requests are sent with http.DefaultTransport.
+func New(path string, mode Mode) (*Transport, error) {
+	t := &Transport{mode: mode, path: path, next: http.DefaultTransport}
+
+	if mode == ModeRecord {
+		return t, nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &t.fixture); err != nil {
+		return nil, fmt.Errorf("replay: %s: %w", path, err)
+	}
+
+	t.used = make([]bool, len(t.fixture.Interactions))
+
+	return t, nil
+}
+
+//WithTransport changes the transport used to send requests in record mode.
+func (t *Transport) WithTransport(next http.RoundTripper) {
+	t.next = next
+}
+
+//Client returns an http.Client using the transport, ready for maclookup.Client.WithHTTPClient.
+func (t *Transport) Client() *http.Client {
+	return &http.Client{Transport: t}
+}
+
+//Interactions returns the recorded interactions.
+func (t *Transport) Interactions() []Interaction {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return append([]Interaction(nil), t.fixture.Interactions...)
+}
+
+//Unused returns the recorded interactions that were not replayed yet.
+//In record mode every interaction is used and it returns nil.
+func (t *Transport) Unused() []Interaction {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var unused []Interaction
+
+	for i, it := range t.fixture.Interactions {
+		if !t.used[i] {
+			unused = append(unused, it)
+		}
+	}
+
+	return unused
+}
+
+//RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.mode == ModeRecord {
+		return t.record(req)
+	}
+
+	return t.replay(req)
+}
+
+func (t *Transport) record(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	it := Interaction{
+		Method:  req.Method,
+		URL:     RedactURL(req.URL),
+		Status:  resp.StatusCode,
+		Headers: make(map[string][]string),
+		Body:    string(body),
+	}
+
+	for _, h := range recordedHeaders {
+		if v := resp.Header.Values(h); len(v) > 0 {
+			it.Headers[h] = v
+		}
+	}
+
+	t.mu.Lock()
+	t.fixture.Interactions = append(t.fixture.Interactions, it)
+	t.used = append(t.used, true)
+	err = t.save()
+	t.mu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	return resp, nil
+}
+
+func (t *Transport) replay(req *http.Request) (*http.Response, error) {
+	key := RedactURL(req.URL)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i, it := range t.fixture.Interactions {
+		if t.used[i] || it.Method != req.Method || it.URL != key {
+			continue
+		}
+
+		t.used[i] = true
+
+		header := make(http.Header)
+		for k, v := range it.Headers {
+			header[http.CanonicalHeaderKey(k)] = v
+		}
+
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", it.Status, http.StatusText(it.Status)),
+			StatusCode:    it.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          ioutil.NopCloser(strings.NewReader(it.Body)),
+			ContentLength: int64(len(it.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, &UnmatchedRequestError{Method: req.Method, URL: key}
+}
+
+func (t *Transport) save() error {
+	data, err := json.MarshalIndent(t.fixture, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(t.path), 0o755); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(t.path, append(data, '\n'), 0o644)
+}
+
+//RedactURL returns the path and query of u with the apiKey parameter redacted.
+//The host is dropped so that fixtures replay against any prefix URI.
+func RedactURL(u *url.URL) string {
+	q := u.Query()
+	if q.Get(apiKeyParam) != "" {
+		q.Set(apiKeyParam, redacted)
+	}
+
+	if len(q) == 0 {
+		return u.EscapedPath()
+	}
+
+	return u.EscapedPath() + "?" + q.Encode()
+}
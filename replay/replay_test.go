@@ -0,0 +1,94 @@
+package replay
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/logocomune/maclookup-go"
+	"github.com/logocomune/maclookup-go/maclookuptest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransport_RecordReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixtures", "lookup.json")
+
+	s := maclookuptest.NewServer()
+	s.WithAPIKeys("SECRET")
+	s.WithRateLimit(10, time.Minute)
+
+	rec, err := New(path, ModeRecord)
+	assert.Nil(t, err)
+
+	client := s.NewClient()
+	client.WithAPIKey("SECRET")
+	client.WithHTTPClient(rec.Client())
+
+	recorded, err := client.Lookup("000000")
+	assert.Nil(t, err)
+
+	_, err = client.CompanyName("ACDE48")
+	assert.Nil(t, err)
+
+	client.WithAPIKey("WRONG")
+	_, err = client.CompanyName("000000")
+
+	var keyErr *maclookup.BadAPIKey
+
+	assert.True(t, errors.As(err, &keyErr))
+	s.Close()
+
+	data, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(data), "SECRET")
+	assert.Contains(t, string(data), "apiKey=REDACTED")
+	assert.Contains(t, string(data), "X-RateLimit-Remaining")
+	assert.Len(t, rec.Interactions(), 3)
+	assert.Empty(t, rec.Unused())
+
+	rep, err := New(path, ModeReplay)
+	assert.Nil(t, err)
+
+	client = maclookup.New()
+	client.WithPrefixURI("https://api.example.org")
+	client.WithAPIKey("ANOTHER_KEY")
+	client.WithHTTPClient(rep.Client())
+
+	replayed, err := client.Lookup("000000")
+	assert.Nil(t, err)
+	assert.Equal(t, recorded.MACInfo, replayed.MACInfo)
+	assert.Equal(t, recorded.RateLimit, replayed.RateLimit)
+
+	name, err := client.CompanyName("ACDE48")
+	assert.Nil(t, err)
+	assert.True(t, name.IsPrivate)
+
+	_, err = client.CompanyName("000000")
+	assert.True(t, errors.As(err, &keyErr))
+	assert.Empty(t, rep.Unused())
+
+	_, err = client.Lookup("000000")
+
+	var unmatched *UnmatchedRequestError
+
+	assert.True(t, errors.As(err, &unmatched))
+	assert.Equal(t, "/v2/macs/000000?apiKey=REDACTED", unmatched.URL)
+}
+
+func TestNew_MissingFixture(t *testing.T) {
+	_, err := New(filepath.Join(t.TempDir(), "missing.json"), ModeReplay)
+	assert.NotNil(t, err)
+}
+
+func TestRedactURL(t *testing.T) {
+	u, _ := url.Parse("https://api.maclookup.app/v2/macs/000000/company/name?apiKey=abc")
+	assert.Equal(t, "/v2/macs/000000/company/name?apiKey=REDACTED", RedactURL(u))
+
+	u, _ = url.Parse("https://api.maclookup.app/v2/macs/000000")
+	assert.Equal(t, "/v2/macs/000000", RedactURL(u))
+	assert.False(t, strings.Contains(RedactURL(u), "?"))
+}
@@ -0,0 +1,58 @@
+package maclookup
+
+import "time"
+
+// begin prepares a request to endpoint for mac: it picks the API key and checks the circuit breaker.
+// It must be called on the copy of the Client serving the request.
+func (c *Client) begin(endpoint, mac string) (*call, error) {
+	if err := c.usePoolKey(); err != nil {
+		return nil, err
+	}
+
+	generation, err := c.breaker.allow()
+	if err != nil {
+		c.refundPoolKey()
+		c.startCall(endpoint, cleanMac(mac)).reject(err)
+
+		return nil, err
+	}
+
+	cl := c.startCall(endpoint, cleanMac(mac))
+	cl.generation = generation
+
+	return cl, nil
+}
+
+// end records the result of a request started with begin, answered by a, and returns err without the API key.
+func (c Client) end(cl *call, a *attempt, found, private bool, respTime time.Duration, rl RateLimit, err error) error {
+	err = c.redact(err)
+
+	c.breaker.record(cl.generation, err)
+	c.releasePoolKey(rl, err)
+	cl.done(a, found, private, respTime, err)
+
+	return err
+}
+
+// annotate returns the Annotations of the result info of a request for mac.
+func (c Client) annotate(mac string, info MACInfo) Annotations {
+	a := Annotations{Virtual: DetectVirtualInfo(mac, info)}
+
+	if c.classifier != nil {
+		a.Classification = c.classifier.Classify(info.Company, mac)
+	}
+
+	if info.Company == "" {
+		return a
+	}
+
+	if c.normalizer != nil {
+		a.Vendor = c.normalizer.Normalize(info.Company)
+	}
+
+	if c.ownership != nil {
+		a.Owners = c.ownership.Chain(info.Company, mac)
+	}
+
+	return a
+}
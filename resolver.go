@@ -0,0 +1,19 @@
+package maclookup
+
+//MACInfoResolver retrieves full MAC information. Client satisfies it.
+type MACInfoResolver interface {
+	Lookup(mac string) (ResponseMACInfo, error)
+}
+
+//CompanyNameResolver retrieves the company name of a MAC. Client satisfies it.
+type CompanyNameResolver interface {
+	CompanyName(mac string) (ResponseVendorName, error)
+}
+
+//Resolver is implemented by Client and by test fakes such as maclookuptest.Fake.
+type Resolver interface {
+	MACInfoResolver
+	CompanyNameResolver
+}
+
+var _ Resolver = Client{}
@@ -0,0 +1,17 @@
+package maclookup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_Resolver(t *testing.T) {
+	var r Resolver = New()
+
+	_, ok := r.(MACInfoResolver)
+	assert.True(t, ok)
+
+	_, ok = r.(CompanyNameResolver)
+	assert.True(t, ok)
+}
@@ -0,0 +1,3 @@
+// Package server implements the MACLookup API v2 JSON contract from IEEE registry CSV files,
+// for sites without access to api.maclookup.app.
+package server
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/logocomune/maclookup-go/internal/apiv2"
+	"github.com/logocomune/maclookup-go/registry"
+)
+
+//Server is an http.Handler implementing the MACLookup API v2 MAC endpoints from an offline registry.
+//Existing clients, including maclookup.Client via WithPrefixURI, work unchanged against it.
+type Server struct {
+	registry *registry.Registry
+
+	mu      sync.RWMutex
+	apiKeys map[string]bool
+	limiter *apiv2.Limiter
+}
+
+//New creates a server answering from reg. Authentication and rate limiting are disabled.
+func New(reg *registry.Registry) *Server {
+	return &Server{registry: reg}
+}
+
+//WithAPIKeys requires one of keys, sent in the apiKey query parameter or the X-Api-Key header, on every request.
+//Requests without a valid key are answered with 401.
+func (s *Server) WithAPIKeys(keys ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.apiKeys = make(map[string]bool, len(keys))
+	for _, k := range keys {
+		s.apiKeys[k] = true
+	}
+}
+
+//WithRateLimit allows limit requests per API key every window and answers 429 afterwards.
+//Every response then carries the X-RateLimit-* headers. Requests without a key share one budget.
+func (s *Server) WithRateLimit(limit int64, window time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.limiter = apiv2.NewLimiter(limit, window)
+}
+
+//ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	req, ok := apiv2.ParseRequest(r)
+	if !ok || (r.Method != http.MethodGet && r.Method != http.MethodHead) {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.mu.RLock()
+	apiKeys, limiter := s.apiKeys, s.limiter
+	s.mu.RUnlock()
+
+	if len(apiKeys) > 0 && !apiKeys[req.APIKey] {
+		apiv2.WriteError(w, req.CompanyName, http.StatusUnauthorized, apiv2.Unauthorized())
+		return
+	}
+
+	if limiter != nil && !limiter.Allow(req.APIKey, w.Header()) {
+		apiv2.WriteError(w, req.CompanyName, http.StatusTooManyRequests, apiv2.TooManyRequests())
+		return
+	}
+
+	mac := apiv2.CleanMAC(req.MAC)
+	if e := apiv2.ValidateMAC(mac); e != nil {
+		apiv2.WriteError(w, req.CompanyName, http.StatusBadRequest, *e)
+		return
+	}
+
+	info, _ := s.registry.Lookup(mac)
+	apiv2.WriteMACInfo(w, req.CompanyName, mac, info)
+}
@@ -0,0 +1,112 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/logocomune/maclookup-go"
+	"github.com/logocomune/maclookup-go/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestServer(t *testing.T) (*Server, *maclookup.Client) {
+	reg, err := registry.LoadFiles("../registry/testdata/oui.csv", "../registry/testdata/oui36.csv")
+	assert.Nil(t, err)
+
+	s := New(reg)
+	ts := httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+
+	client := maclookup.New()
+	client.WithPrefixURI(ts.URL)
+
+	return s, client
+}
+
+func TestServer_Lookup(t *testing.T) {
+	_, client := newTestServer(t)
+
+	r, err := client.Lookup("00:00:00:11:22:33")
+	assert.Nil(t, err)
+	assert.True(t, r.Found)
+	assert.Equal(t, "XEROX CORPORATION", r.Company)
+	assert.Equal(t, "US", r.Country)
+	assert.Equal(t, "MA-L", r.BlockType)
+	assert.Equal(t, int64(-1), r.Limit)
+
+	r, err = client.Lookup("70B3D5F2F001")
+	assert.Nil(t, err)
+	assert.Equal(t, "70B3D5F2F", r.MacPrefix)
+
+	r, err = client.Lookup("02:00:00")
+	assert.Nil(t, err)
+	assert.False(t, r.Found)
+	assert.True(t, r.IsRand)
+
+	_, err = client.Lookup("00")
+
+	var e *maclookup.BadAPIRequest
+
+	assert.True(t, errors.As(err, &e))
+}
+
+func TestServer_CompanyName(t *testing.T) {
+	_, client := newTestServer(t)
+
+	r, err := client.CompanyName("000000")
+	assert.Nil(t, err)
+	assert.Equal(t, "XEROX CORPORATION", r.Company)
+
+	r, err = client.CompanyName("ACDE48")
+	assert.Nil(t, err)
+	assert.True(t, r.IsPrivate)
+
+	r, err = client.CompanyName("010000")
+	assert.Nil(t, err)
+	assert.False(t, r.Found)
+}
+
+func TestServer_AuthAndRateLimit(t *testing.T) {
+	s, client := newTestServer(t)
+	s.WithAPIKeys("KEY1", "KEY2")
+	s.WithRateLimit(1, time.Minute)
+
+	_, err := client.Lookup("000000")
+
+	var keyErr *maclookup.BadAPIKey
+
+	assert.True(t, errors.As(err, &keyErr))
+
+	client.WithAPIKey("KEY1")
+
+	r, err := client.Lookup("000000")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), r.Limit)
+	assert.Equal(t, int64(0), r.Remaining)
+
+	_, err = client.CompanyName("000000")
+
+	var rateErr *maclookup.RateLimitsExceeded
+
+	assert.True(t, errors.As(err, &rateErr))
+
+	client.WithAPIKey("KEY2")
+
+	_, err = client.CompanyName("000000")
+	assert.Nil(t, err)
+}
+
+func TestServer_NotFound(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v2/macs/000000", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v2/other", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,360 @@
+package maclookup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+//DayFormat is the layout of UsageRecord.Day. Days are UTC.
+const DayFormat = "2006-01-02"
+
+const (
+	usageVersion      = 1
+	usageSaveInterval = time.Second
+)
+
+//UsageRecord is the number of requests sent to an endpoint with an API key in a day.
+type UsageRecord struct {
+	Day      string `json:"day"`
+	Endpoint string `json:"endpoint"`
+	Key      string `json:"key"`
+	Requests int64  `json:"requests"`
+	Errors   int64  `json:"errors"`
+}
+
+//UsageReport aggregates the records of a period.
+type UsageReport struct {
+	Records    []UsageRecord
+	ByDay      map[string]int64
+	ByEndpoint map[string]int64
+	ByKey      map[string]int64
+	Total      int64
+	Errors     int64
+}
+
+//UsageAlert is sent when the requests of a day reach a threshold.
+type UsageAlert struct {
+	Day       string
+	Threshold int64
+	Requests  int64
+}
+
+//Usage counts the requests of one or more clients per day, endpoint and API key,
+//and optionally persists them to a JSON file. API keys are stored as KeyLabel.
+type Usage struct {
+	mu       sync.Mutex
+	path     string
+	records  map[usageKey]*UsageRecord
+	totals   map[string]int64
+	lastSave time.Time
+	snapshot uint64
+	now      func() time.Time
+
+	// saveMu serializes the writes of the file, done without mu.
+	saveMu sync.Mutex
+	saved  uint64
+
+	thresholds  []int64
+	onThreshold func(UsageAlert)
+
+	floor          int64
+	onLowRemaining func(key string, rl RateLimit)
+	low            map[string]bool
+}
+
+type usageKey struct {
+	day      string
+	endpoint string
+	key      string
+}
+
+type usageFile struct {
+	Version int           `json:"version"`
+	Records []UsageRecord `json:"records"`
+}
+
+//NewUsage creates an in-memory usage tracker.
+func NewUsage() *Usage {
+	return &Usage{
+		records: make(map[usageKey]*UsageRecord),
+		totals:  make(map[string]int64),
+		low:     make(map[string]bool),
+		now:     time.Now,
+	}
+}
+
+//OpenUsage creates a usage tracker persisted to path, loading the records already saved there.
+//Records are saved at most once per second while requests are recorded: call Save before exiting.
+func OpenUsage(path string) (*Usage, error) {
+	u := NewUsage()
+	u.path = path
+
+	data, err := ioutil.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return u, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var f usageFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, err
+	}
+
+	for _, r := range f.Records {
+		r := r
+		u.records[usageKey{day: r.Day, endpoint: r.Endpoint, key: r.Key}] = &r
+		u.totals[r.Day] += r.Requests
+	}
+
+	return u, nil
+}
+
+//WithUsage records every request sent by the client in u. The same Usage can be shared by several clients.
+func (c *Client) WithUsage(u *Usage) {
+	c.usage = u
+}
+
+//OnThreshold calls f once when the requests of a day, across all endpoints and keys, reach each of thresholds.
+func (u *Usage) OnThreshold(f func(UsageAlert), thresholds ...int64) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.onThreshold = f
+	u.thresholds = append([]int64(nil), thresholds...)
+	sort.Slice(u.thresholds, func(i, j int) bool { return u.thresholds[i] < u.thresholds[j] })
+}
+
+//OnLowRemaining calls f when the RateLimit.Remaining of a key drops below floor.
+//f is called again for the same key only after Remaining went back to floor or above.
+func (u *Usage) OnLowRemaining(floor int64, f func(key string, rl RateLimit)) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.floor = floor
+	u.onLowRemaining = f
+}
+
+//KeyLabel returns the identifier of an API key in usage records and key pools:
+//"key-" followed by the first 8 hex digits of its SHA-256.
+func KeyLabel(key string) string {
+	if key == "" {
+		return ""
+	}
+
+	sum := sha256.Sum256([]byte(key))
+
+	return "key-" + hex.EncodeToString(sum[:4])
+}
+
+func (u *Usage) record(endpoint, key string, rl RateLimit, err error) {
+	if u == nil {
+		return
+	}
+
+	label := KeyLabel(key)
+
+	u.mu.Lock()
+
+	now := u.now()
+	day := now.UTC().Format(DayFormat)
+	k := usageKey{day: day, endpoint: endpoint, key: label}
+
+	r, ok := u.records[k]
+	if !ok {
+		r = &UsageRecord{Day: day, Endpoint: endpoint, Key: label}
+		u.records[k] = r
+	}
+
+	r.Requests++
+	u.totals[day]++
+
+	if err != nil {
+		r.Errors++
+	}
+
+	var alerts []UsageAlert
+
+	if u.onThreshold != nil {
+		total := u.totals[day]
+		for _, t := range u.thresholds {
+			if total == t {
+				alerts = append(alerts, UsageAlert{Day: day, Threshold: t, Requests: total})
+			}
+		}
+	}
+
+	var lowRemaining bool
+
+	if u.onLowRemaining != nil && rl.Remaining >= 0 && !rl.Reset.IsZero() {
+		below := rl.Remaining < u.floor
+		lowRemaining = below && !u.low[label]
+		u.low[label] = below
+	}
+
+	onThreshold, onLowRemaining := u.onThreshold, u.onLowRemaining
+
+	var (
+		save bool
+		f    usageFile
+		seq  uint64
+	)
+
+	if u.path != "" && now.Sub(u.lastSave) >= usageSaveInterval {
+		save = true
+		u.lastSave = now
+		f, seq = u.takeSnapshot()
+	}
+
+	u.mu.Unlock()
+
+	// A failed save is retried on the next request and reported by Save.
+	if save && u.write(f, seq) != nil {
+		u.mu.Lock()
+		u.lastSave = time.Time{}
+		u.mu.Unlock()
+	}
+
+	for _, a := range alerts {
+		onThreshold(a)
+	}
+
+	if lowRemaining {
+		onLowRemaining(label, rl)
+	}
+}
+
+//Report aggregates the records of the days between from and to, inclusive.
+//Zero times leave the period unbounded.
+func (u *Usage) Report(from, to time.Time) UsageReport {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	report := UsageReport{
+		ByDay:      make(map[string]int64),
+		ByEndpoint: make(map[string]int64),
+		ByKey:      make(map[string]int64),
+	}
+
+	fromDay, toDay := "", ""
+	if !from.IsZero() {
+		fromDay = from.UTC().Format(DayFormat)
+	}
+
+	if !to.IsZero() {
+		toDay = to.UTC().Format(DayFormat)
+	}
+
+	for _, r := range u.records {
+		if (fromDay != "" && r.Day < fromDay) || (toDay != "" && r.Day > toDay) {
+			continue
+		}
+
+		report.Records = append(report.Records, *r)
+		report.ByDay[r.Day] += r.Requests
+		report.ByEndpoint[r.Endpoint] += r.Requests
+		report.ByKey[r.Key] += r.Requests
+		report.Total += r.Requests
+		report.Errors += r.Errors
+	}
+
+	sortRecords(report.Records)
+
+	return report
+}
+
+//Save writes the records to the file given to OpenUsage. It does nothing for in-memory trackers.
+func (u *Usage) Save() error {
+	u.mu.Lock()
+
+	if u.path == "" {
+		u.mu.Unlock()
+		return nil
+	}
+
+	f, seq := u.takeSnapshot()
+	u.mu.Unlock()
+
+	return u.write(f, seq)
+}
+
+// takeSnapshot copies the records to save with their sequence number. It must be called with u.mu held.
+func (u *Usage) takeSnapshot() (usageFile, uint64) {
+	f := usageFile{Version: usageVersion, Records: make([]UsageRecord, 0, len(u.records))}
+	for _, r := range u.records {
+		f.Records = append(f.Records, *r)
+	}
+
+	u.snapshot++
+
+	return f, u.snapshot
+}
+
+// write writes the snapshot f atomically, through a temporary file in the same directory,
+// unless a later snapshot was already written.
+func (u *Usage) write(f usageFile, seq uint64) error {
+	u.saveMu.Lock()
+	defer u.saveMu.Unlock()
+
+	if seq <= u.saved {
+		return nil
+	}
+
+	sortRecords(f.Records)
+
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(u.path), filepath.Base(u.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), u.path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	u.saved = seq
+
+	return nil
+}
+
+func sortRecords(records []UsageRecord) {
+	sort.Slice(records, func(i, j int) bool {
+		a, b := records[i], records[j]
+		if a.Day != b.Day {
+			return a.Day < b.Day
+		}
+
+		if a.Endpoint != b.Endpoint {
+			return a.Endpoint < b.Endpoint
+		}
+
+		return a.Key < b.Key
+	})
+}
@@ -0,0 +1,107 @@
+package maclookup
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_WithUsage(t *testing.T) {
+	remaining := 5
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		remaining--
+		w.Header().Add(xRateLimit, "5")
+		w.Header().Add(xRateRemaining, fmt.Sprintf("%d", remaining))
+		w.Header().Add(xRateReset, fmt.Sprintf("%d", time.Now().Add(time.Hour).Unix()))
+
+		if r.URL.Path == "/v2/macs/000000/company/name" {
+			fmt.Fprint(w, `XEROX CORPORATION`)
+			return
+		}
+
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintln(w, `{"success":false,"error":"MAC must be greater than 5 chars","errorCode":101}`)
+	}))
+	defer ts.Close()
+
+	path := filepath.Join(t.TempDir(), "usage.json")
+	usage, err := OpenUsage(path)
+	assert.Nil(t, err)
+
+	var (
+		alerts []UsageAlert
+		low    []int64
+	)
+
+	usage.OnThreshold(func(a UsageAlert) { alerts = append(alerts, a) }, 3, 2)
+	usage.OnLowRemaining(2, func(key string, rl RateLimit) {
+		assert.Equal(t, KeyLabel("SECRET_KEY"), key)
+
+		low = append(low, rl.Remaining)
+	})
+
+	client := New()
+	client.WithPrefixURI(ts.URL)
+	client.WithAPIKey("SECRET_KEY")
+	client.WithUsage(usage)
+
+	_, _ = client.CompanyName("000000")
+	_, _ = client.CompanyName("000000")
+	_, _ = client.CompanyName("000000")
+	_, _ = client.Lookup("0000")
+
+	day := time.Now().UTC().Format(DayFormat)
+
+	assert.Equal(t, []UsageAlert{{Day: day, Threshold: 2, Requests: 2}, {Day: day, Threshold: 3, Requests: 3}}, alerts)
+	assert.Equal(t, []int64{1}, low)
+	assert.Nil(t, usage.Save())
+
+	reopened, err := OpenUsage(path)
+	assert.Nil(t, err)
+
+	report := reopened.Report(time.Time{}, time.Time{})
+	assert.Equal(t, int64(4), report.Total)
+	assert.Equal(t, int64(1), report.Errors)
+	assert.Equal(t, int64(3), report.ByEndpoint[EndpointCompanyName])
+	assert.Equal(t, int64(4), report.ByKey[KeyLabel("SECRET_KEY")])
+	assert.Equal(t, int64(4), report.ByDay[day])
+	assert.Equal(t, []UsageRecord{
+		{Day: day, Endpoint: EndpointCompanyName, Key: KeyLabel("SECRET_KEY"), Requests: 3},
+		{Day: day, Endpoint: EndpointLookup, Key: KeyLabel("SECRET_KEY"), Requests: 1, Errors: 1},
+	}, report.Records)
+
+	report = reopened.Report(time.Now().Add(48*time.Hour), time.Time{})
+	assert.Equal(t, int64(0), report.Total)
+}
+
+func TestUsage_days(t *testing.T) {
+	now := time.Date(2026, 3, 1, 23, 30, 0, 0, time.UTC)
+	usage := NewUsage()
+	usage.now = func() time.Time { return now }
+
+	usage.record(EndpointLookup, "", RateLimit{}, nil)
+	now = now.Add(time.Hour)
+	usage.record(EndpointLookup, "", RateLimit{}, nil)
+	usage.record(EndpointLookup, "", RateLimit{}, nil)
+
+	report := usage.Report(time.Time{}, time.Time{})
+	assert.Equal(t, map[string]int64{"2026-03-01": 1, "2026-03-02": 2}, report.ByDay)
+
+	report = usage.Report(now, now)
+	assert.Equal(t, int64(2), report.Total)
+	assert.Nil(t, usage.Save())
+}
+
+func TestKeyLabel(t *testing.T) {
+	assert.Equal(t, "", KeyLabel(""))
+	assert.Equal(t, "key-88d4266f", KeyLabel("abcd"))
+	assert.NotContains(t, KeyLabel("SECRET_KEY"), "KEY")
+
+	// Keys ending with the same characters have distinct labels.
+	assert.NotEqual(t, KeyLabel("team_a_abcd"), KeyLabel("team_b_abcd"))
+}
@@ -0,0 +1,152 @@
+package maclookup
+
+import (
+	"strings"
+
+	"github.com/logocomune/maclookup-go/internal/macaddr"
+)
+
+//Platform is a hypervisor or container runtime.
+type Platform string
+
+//Virtualization platforms detected by DetectVirtual.
+const (
+	PlatformVMware     Platform = "vmware"
+	PlatformHyperV     Platform = "hyper-v"
+	PlatformKVM        Platform = "kvm"
+	PlatformXen        Platform = "xen"
+	PlatformVirtualBox Platform = "virtualbox"
+	PlatformParallels  Platform = "parallels"
+	PlatformDocker     Platform = "docker"
+)
+
+//VirtualKind tells virtual machines from containers.
+type VirtualKind string
+
+//Kinds of virtual NICs.
+const (
+	KindVM        VirtualKind = "vm"
+	KindContainer VirtualKind = "container"
+)
+
+//VirtualNIC is the virtualization platform of a MAC address with a confidence between 0 and 1.
+//The zero VirtualNIC is a physical (or unknown) NIC.
+type VirtualNIC struct {
+	Platform   Platform    `json:"platform"`
+	Kind       VirtualKind `json:"kind"`
+	Confidence float64     `json:"confidence"`
+}
+
+// virtualRange is a MAC prefix used by a virtualization platform, with the normalized
+// company keys of its registry assignment, when assigned.
+type virtualRange struct {
+	prefix    string
+	platform  Platform
+	kind      VirtualKind
+	companies []string
+}
+
+var virtualRanges = []virtualRange{
+	{prefix: "005056", platform: PlatformVMware, kind: KindVM, companies: []string{"vmware"}},
+	{prefix: "000C29", platform: PlatformVMware, kind: KindVM, companies: []string{"vmware"}},
+	{prefix: "000569", platform: PlatformVMware, kind: KindVM, companies: []string{"vmware"}},
+	{prefix: "001C14", platform: PlatformVMware, kind: KindVM, companies: []string{"vmware"}},
+	{prefix: "00155D", platform: PlatformHyperV, kind: KindVM, companies: []string{"microsoft"}},
+	{prefix: "525400", platform: PlatformKVM, kind: KindVM},
+	{prefix: "00163E", platform: PlatformXen, kind: KindVM, companies: []string{"xensource"}},
+	{prefix: "080027", platform: PlatformVirtualBox, kind: KindVM, companies: []string{"pcs systemtechnik", "oracle"}},
+	{prefix: "0A0027", platform: PlatformVirtualBox, kind: KindVM},
+	{prefix: "001C42", platform: PlatformParallels, kind: KindVM, companies: []string{"parallels"}},
+	{prefix: "0242", platform: PlatformDocker, kind: KindContainer},
+}
+
+// virtualVendors are the companies of virtualization platforms, for the assignments missing from virtualRanges.
+var virtualVendors = map[string]Platform{
+	"vmware":    PlatformVMware,
+	"xensource": PlatformXen,
+	"parallels": PlatformParallels,
+}
+
+// Confidences of DetectVirtual and DetectVirtualInfo.
+const (
+	virtualVMConfidence        = 0.9
+	virtualContainerConfidence = 0.8
+	virtualLocalConfidence     = 0.9
+	virtualConfirmedConfidence = 1
+	virtualVendorConfidence    = 0.7
+)
+
+//DetectVirtual detects offline, from the MAC address alone, the NICs of VMware, Hyper-V, KVM/QEMU (52:54:00),
+//Xen, VirtualBox and Parallels virtual machines and of Docker containers (02:42, locally administered).
+func DetectVirtual(mac string) VirtualNIC {
+	r, ok := findVirtualRange(mac)
+	if !ok {
+		return VirtualNIC{}
+	}
+
+	confidence := virtualVMConfidence
+	if r.kind == KindContainer {
+		confidence = virtualContainerConfidence
+	}
+
+	return VirtualNIC{Platform: r.platform, Kind: r.kind, Confidence: confidence}
+}
+
+//DetectVirtualInfo combines DetectVirtual with the result of a lookup of mac. The company of the assignment
+//confirms the platform of a registered prefix and IsRand (locally administered) the platform of an unregistered one.
+//A virtualization vendor on another prefix is detected with a lower confidence, unless the assignment is private.
+func DetectVirtualInfo(mac string, info MACInfo) VirtualNIC {
+	key := NormalizeCompany(info.Company).Key
+
+	r, ok := findVirtualRange(mac)
+	if !ok {
+		for vendor, platform := range virtualVendors {
+			if hasWordPrefix(key, vendor) && !info.IsPrivate {
+				return VirtualNIC{Platform: platform, Kind: KindVM, Confidence: virtualVendorConfidence}
+			}
+		}
+
+		return VirtualNIC{}
+	}
+
+	v := DetectVirtual(mac)
+
+	if len(r.companies) == 0 && info.IsRand && key == "" {
+		v.Confidence = virtualLocalConfidence
+	}
+
+	for _, c := range r.companies {
+		if hasWordPrefix(key, c) {
+			v.Confidence = virtualConfirmedConfidence
+		}
+	}
+
+	return v
+}
+
+// virtualPrefixes returns the prefixes of virtualRanges.
+func virtualPrefixes() []string {
+	prefixes := make([]string, len(virtualRanges))
+	for i, r := range virtualRanges {
+		prefixes[i] = r.prefix
+	}
+
+	return prefixes
+}
+
+func findVirtualRange(mac string) (virtualRange, bool) {
+	m := macaddr.Clean(mac)
+
+	for _, r := range virtualRanges {
+		if strings.HasPrefix(m, r.prefix) {
+			return r, true
+		}
+	}
+
+	return virtualRange{}, false
+}
+
+// hasWordPrefix reports whether the words of s start with the words of prefix.
+func hasWordPrefix(s, prefix string) bool {
+	return s == prefix || strings.HasPrefix(s, prefix+" ")
+}
@@ -0,0 +1,85 @@
+package maclookup
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectVirtual(t *testing.T) {
+	tests := []struct {
+		mac  string
+		want VirtualNIC
+	}{
+		{"00:50:56:01:02:03", VirtualNIC{Platform: PlatformVMware, Kind: KindVM, Confidence: 0.9}},
+		{"00-0c-29-aa-bb-cc", VirtualNIC{Platform: PlatformVMware, Kind: KindVM, Confidence: 0.9}},
+		{"00:15:5d:00:01:02", VirtualNIC{Platform: PlatformHyperV, Kind: KindVM, Confidence: 0.9}},
+		{"52:54:00:12:34:56", VirtualNIC{Platform: PlatformKVM, Kind: KindVM, Confidence: 0.9}},
+		{"00:16:3e:12:34:56", VirtualNIC{Platform: PlatformXen, Kind: KindVM, Confidence: 0.9}},
+		{"08:00:27:12:34:56", VirtualNIC{Platform: PlatformVirtualBox, Kind: KindVM, Confidence: 0.9}},
+		{"00:1c:42:12:34:56", VirtualNIC{Platform: PlatformParallels, Kind: KindVM, Confidence: 0.9}},
+		{"02:42:ac:11:00:02", VirtualNIC{Platform: PlatformDocker, Kind: KindContainer, Confidence: 0.8}},
+		{"0242.ac11.0002", VirtualNIC{Platform: PlatformDocker, Kind: KindContainer, Confidence: 0.8}},
+		{"00:00:00:12:34:56", VirtualNIC{}},
+		{"02:43:ac:11:00:02", VirtualNIC{}},
+		{"52:54", VirtualNIC{}},
+		{"", VirtualNIC{}},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, DetectVirtual(tt.mac), tt.mac)
+	}
+}
+
+func TestDetectVirtualInfo(t *testing.T) {
+	tests := []struct {
+		mac  string
+		info MACInfo
+		want VirtualNIC
+	}{
+		{"00:50:56:01:02:03", MACInfo{Found: true, Company: "VMware, Inc."}, VirtualNIC{Platform: PlatformVMware, Kind: KindVM, Confidence: 1}},
+		{"00:15:5d:00:01:02", MACInfo{Found: true, Company: "Microsoft Corporation"}, VirtualNIC{Platform: PlatformHyperV, Kind: KindVM, Confidence: 1}},
+		{"08:00:27:12:34:56", MACInfo{Found: true, Company: "PCS Systemtechnik GmbH"}, VirtualNIC{Platform: PlatformVirtualBox, Kind: KindVM, Confidence: 1}},
+		{"02:42:ac:11:00:02", MACInfo{IsRand: true}, VirtualNIC{Platform: PlatformDocker, Kind: KindContainer, Confidence: 0.9}},
+		{"52:54:00:12:34:56", MACInfo{IsRand: true}, VirtualNIC{Platform: PlatformKVM, Kind: KindVM, Confidence: 0.9}},
+		{"02:42:ac:11:00:02", MACInfo{}, VirtualNIC{Platform: PlatformDocker, Kind: KindContainer, Confidence: 0.8}},
+		{"00:50:56:01:02:03", MACInfo{}, VirtualNIC{Platform: PlatformVMware, Kind: KindVM, Confidence: 0.9}},
+		{"00:0c:29:01:02:03", MACInfo{Found: true, Company: "Acme"}, VirtualNIC{Platform: PlatformVMware, Kind: KindVM, Confidence: 0.9}},
+		{"AA:BB:CC:01:02:03", MACInfo{Found: true, Company: "VMware, Inc."}, VirtualNIC{Platform: PlatformVMware, Kind: KindVM, Confidence: 0.7}},
+		{"AA:BB:CC:01:02:03", MACInfo{Found: true, Company: "Parallels, Inc."}, VirtualNIC{Platform: PlatformParallels, Kind: KindVM, Confidence: 0.7}},
+		{"AA:BB:CC:01:02:03", MACInfo{Found: true, IsPrivate: true, Company: "VMware, Inc."}, VirtualNIC{}},
+		{"AA:BB:CC:01:02:03", MACInfo{Found: true, Company: "VMwarez Ltd"}, VirtualNIC{}},
+		{"00:00:00:01:02:03", MACInfo{Found: true, Company: "XEROX CORPORATION"}, VirtualNIC{}},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, DetectVirtualInfo(tt.mac, tt.info), "%s %+v", tt.mac, tt.info)
+	}
+}
+
+func TestClient_LookupVirtual(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, companyNameSuffix) {
+			fmt.Fprint(w, "*NO COMPANY*")
+			return
+		}
+
+		fmt.Fprintln(w, `{"success":true,"found":false,"macPrefix":"","company":"","isRand":true}`)
+	}))
+	defer ts.Close()
+
+	client := New()
+	client.WithPrefixURI(ts.URL)
+
+	resp, err := client.Lookup("02:42:ac:11:00:02")
+	assert.Nil(t, err)
+	assert.Equal(t, VirtualNIC{Platform: PlatformDocker, Kind: KindContainer, Confidence: 0.9}, resp.Virtual)
+
+	name, err := client.CompanyName("02:42:ac:11:00:02")
+	assert.Nil(t, err)
+	assert.Equal(t, VirtualNIC{Platform: PlatformDocker, Kind: KindContainer, Confidence: 0.8}, name.Virtual)
+}
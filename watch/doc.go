@@ -0,0 +1,5 @@
+// Package watch monitors the neighbors of a host (/proc/net/arp and the neighbor table of "ip neigh"),
+// resolves the vendor of each new MAC address with a maclookup.MACInfoResolver and reports the changes
+// as events: new device, vendor never seen before, device gone, device back and MAC-to-IP change.
+// Events can be written as text, as JSON Lines or streamed as Server-Sent Events.
+package watch
@@ -0,0 +1,199 @@
+package watch
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+//ProcARPPath is the ARP table of Linux.
+const ProcARPPath = "/proc/net/arp"
+
+//Neighbor is an IP address with the MAC address it resolves to on an interface.
+type Neighbor struct {
+	IP        string
+	MAC       string
+	Interface string
+}
+
+//Source lists the current neighbors of the host.
+type Source interface {
+	Neighbors(ctx context.Context) ([]Neighbor, error)
+}
+
+//SourceFunc adapts a function to Source.
+type SourceFunc func(ctx context.Context) ([]Neighbor, error)
+
+//Neighbors calls f.
+func (f SourceFunc) Neighbors(ctx context.Context) ([]Neighbor, error) {
+	return f(ctx)
+}
+
+//ProcARP reads an ARP table in the /proc/net/arp format.
+type ProcARP struct {
+	Path string
+}
+
+//Neighbors implements Source.
+func (p ProcARP) Neighbors(context.Context) ([]Neighbor, error) {
+	path := p.Path
+	if path == "" {
+		path = ProcARPPath
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ParseProcARP(f)
+}
+
+//IPNeigh runs "ip neigh show" and parses its output. Command defaults to "ip".
+type IPNeigh struct {
+	Command string
+}
+
+//Neighbors implements Source.
+func (n IPNeigh) Neighbors(ctx context.Context) ([]Neighbor, error) {
+	command := n.Command
+	if command == "" {
+		command = "ip"
+	}
+
+	out, err := exec.CommandContext(ctx, command, "neigh", "show").Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s neigh show: %w", command, err)
+	}
+
+	return ParseIPNeigh(bytes.NewReader(out))
+}
+
+//Merge returns a Source listing the neighbors of sources without duplicates.
+//It fails only when every source fails.
+func Merge(sources ...Source) Source {
+	return SourceFunc(func(ctx context.Context) ([]Neighbor, error) {
+		seen := make(map[Neighbor]bool)
+
+		var (
+			all      []Neighbor
+			firstErr error
+			failed   int
+		)
+
+		for _, s := range sources {
+			list, err := s.Neighbors(ctx)
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+
+				failed++
+
+				continue
+			}
+
+			for _, n := range list {
+				if !seen[n] {
+					seen[n] = true
+					all = append(all, n)
+				}
+			}
+		}
+
+		if failed == len(sources) && firstErr != nil {
+			return nil, firstErr
+		}
+
+		sort.Slice(all, func(i, j int) bool {
+			if all[i].MAC != all[j].MAC {
+				return all[i].MAC < all[j].MAC
+			}
+
+			return all[i].IP < all[j].IP
+		})
+
+		return all, nil
+	})
+}
+
+//ParseProcARP parses an ARP table in the /proc/net/arp format.
+//Incomplete entries (flags 0x0 or a zero MAC address) are skipped.
+func ParseProcARP(r io.Reader) ([]Neighbor, error) {
+	var list []Neighbor
+
+	s := bufio.NewScanner(r)
+
+	for line := 0; s.Scan(); line++ {
+		fields := strings.Fields(s.Text())
+		if line == 0 || len(fields) < 6 {
+			continue
+		}
+
+		if fields[2] == "0x0" {
+			continue
+		}
+
+		if n, ok := newNeighbor(fields[0], fields[3], fields[5]); ok {
+			list = append(list, n)
+		}
+	}
+
+	return list, s.Err()
+}
+
+//ParseIPNeigh parses the output of "ip neigh show" ("IP dev IFACE lladdr MAC [router] STATE").
+//Entries without link layer address and FAILED or INCOMPLETE entries are skipped.
+func ParseIPNeigh(r io.Reader) ([]Neighbor, error) {
+	var list []Neighbor
+
+	s := bufio.NewScanner(r)
+
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) < 2 {
+			continue
+		}
+
+		var iface, mac string
+
+		for i := 1; i+1 < len(fields); i++ {
+			switch fields[i] {
+			case "dev":
+				iface = fields[i+1]
+			case "lladdr":
+				mac = fields[i+1]
+			}
+		}
+
+		switch fields[len(fields)-1] {
+		case "FAILED", "INCOMPLETE":
+			continue
+		}
+
+		if n, ok := newNeighbor(fields[0], mac, iface); ok {
+			list = append(list, n)
+		}
+	}
+
+	return list, s.Err()
+}
+
+func newNeighbor(ip, mac, iface string) (Neighbor, bool) {
+	addr := net.ParseIP(ip)
+	hw, err := net.ParseMAC(mac)
+
+	if addr == nil || err != nil || len(hw) != 6 || bytes.Equal(hw, make([]byte, 6)) {
+		return Neighbor{}, false
+	}
+
+	return Neighbor{IP: addr.String(), MAC: hw.String(), Interface: iface}, true
+}
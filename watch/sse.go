@@ -0,0 +1,97 @@
+package watch
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// sseBuffer is the number of events queued for a client before they are dropped.
+const sseBuffer = 64
+
+//Broadcaster is an http.Handler streaming the events published to it as Server-Sent Events:
+//"id: N", "event: TYPE" and "data: JSON" lines. Events are dropped for clients too slow to read them.
+type Broadcaster struct {
+	mu      sync.Mutex
+	clients map[chan sseEvent]struct{}
+	id      uint64
+}
+
+type sseEvent struct {
+	id uint64
+	e  Event
+}
+
+//NewBroadcaster creates a Broadcaster without clients.
+func NewBroadcaster() *Broadcaster {
+	return &Broadcaster{clients: make(map[chan sseEvent]struct{})}
+}
+
+//Publish sends e to the connected clients.
+func (b *Broadcaster) Publish(e Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.id++
+
+	for c := range b.clients {
+		select {
+		case c <- sseEvent{id: b.id, e: e}:
+		default:
+		}
+	}
+}
+
+//ServeHTTP streams the events published until the client disconnects.
+func (b *Broadcaster) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	c := make(chan sseEvent, sseBuffer)
+
+	b.mu.Lock()
+	b.clients[c] = struct{}{}
+	b.mu.Unlock()
+
+	defer func() {
+		b.mu.Lock()
+		delete(b.clients, c)
+		b.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case ev := <-c:
+			data, err := json.Marshal(ev.e)
+			if err != nil {
+				continue
+			}
+
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", ev.id, ev.e.Type, data); err != nil {
+				return
+			}
+
+			flusher.Flush()
+		}
+	}
+}
+
+//Clients returns the number of connected clients.
+func (b *Broadcaster) Clients() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return len(b.clients)
+}
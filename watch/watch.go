@@ -0,0 +1,456 @@
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/logocomune/maclookup-go"
+)
+
+//StateVersion is the version of the state written by SaveState.
+const StateVersion = 1
+
+//Defaults of a Watcher.
+const (
+	DefaultInterval  = 30 * time.Second
+	DefaultGoneAfter = 5 * time.Minute
+)
+
+//EventType is the kind of change reported by a Watcher.
+type EventType string
+
+//Event types.
+const (
+	//EventNewDevice is a MAC address never seen before.
+	EventNewDevice EventType = "new_device"
+	//EventNewVendor is the first device of a vendor, following its EventNewDevice.
+	EventNewVendor EventType = "new_vendor"
+	//EventDeviceGone is a device missing from the neighbors for the gone delay.
+	EventDeviceGone EventType = "device_gone"
+	//EventDeviceBack is a gone device seen again.
+	EventDeviceBack EventType = "device_back"
+	//EventIPChange is a device with a new IPv4 address (PreviousIP is the old one).
+	EventIPChange EventType = "ip_change"
+)
+
+//Event is a change of the neighbors of the host. Lookup is the result of the lookup of MAC,
+//when resolved, and Error the error of its last lookup.
+type Event struct {
+	Type       EventType                  `json:"type"`
+	Time       time.Time                  `json:"time"`
+	MAC        string                     `json:"mac"`
+	IP         string                     `json:"ip,omitempty"`
+	PreviousIP string                     `json:"previousIP,omitempty"`
+	Interface  string                     `json:"interface,omitempty"`
+	Vendor     string                     `json:"vendor,omitempty"`
+	Lookup     *maclookup.ResponseMACInfo `json:"lookup,omitempty"`
+	Error      string                     `json:"error,omitempty"`
+}
+
+//Device is a MAC address seen by a Watcher. IP is its IPv4 address, or its IPv6 address when it has none.
+type Device struct {
+	MAC       string                    `json:"mac"`
+	IP        string                    `json:"ip,omitempty"`
+	Interface string                    `json:"interface,omitempty"`
+	FirstSeen time.Time                 `json:"firstSeen"`
+	LastSeen  time.Time                 `json:"lastSeen"`
+	Present   bool                      `json:"present"`
+	Resolved  bool                      `json:"resolved"`
+	Info      maclookup.ResponseMACInfo `json:"info"`
+	Error     string                    `json:"error,omitempty"`
+}
+
+// state is the JSON document of SaveState: {"version":1,"devices":[...],"vendors":[...]}.
+type state struct {
+	Version int      `json:"version"`
+	Devices []Device `json:"devices"`
+	Vendors []string `json:"vendors"`
+}
+
+//Watcher polls a Source and reports the changes of its neighbors as events.
+//MAC addresses are resolved once; failed lookups are retried at the next polls.
+type Watcher struct {
+	source    Source
+	resolver  maclookup.MACInfoResolver
+	interval  time.Duration
+	goneAfter time.Duration
+	onError   func(error)
+	onChange  func()
+	now       func() time.Time
+
+	mu      sync.Mutex
+	devices map[string]*Device
+	vendors map[string]bool
+	// pending are the MAC addresses being looked up by a Poll, which the other polls do not look up.
+	pending map[string]bool
+}
+
+//New creates a Watcher of source resolving MAC addresses with resolver, usually a *maclookup.Client.
+func New(source Source, resolver maclookup.MACInfoResolver) *Watcher {
+	return &Watcher{
+		source:    source,
+		resolver:  resolver,
+		interval:  DefaultInterval,
+		goneAfter: DefaultGoneAfter,
+		onError:   func(error) {},
+		onChange:  func() {},
+		now:       time.Now,
+		devices:   make(map[string]*Device),
+		vendors:   make(map[string]bool),
+		pending:   make(map[string]bool),
+	}
+}
+
+//WithInterval sets the delay between two polls of Run.
+func (w *Watcher) WithInterval(d time.Duration) {
+	w.interval = d
+}
+
+//WithGoneAfter sets the delay after which a device missing from the neighbors is gone.
+func (w *Watcher) WithGoneAfter(d time.Duration) {
+	w.goneAfter = d
+}
+
+//OnError sets the function called by Run with the errors of the source.
+func (w *Watcher) OnError(f func(error)) {
+	w.onError = f
+}
+
+//OnChange sets the function called by Run after the events of a poll which changed the devices
+//or vendors seen, e.g. to save the state once per poll.
+func (w *Watcher) OnChange(f func()) {
+	w.onChange = f
+}
+
+//Run polls the source immediately, then every interval, and calls emit with the events until ctx is done.
+func (w *Watcher) Run(ctx context.Context, emit func(Event)) error {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		events, changed, err := w.poll(ctx)
+		if err != nil && ctx.Err() == nil {
+			w.onError(err)
+		}
+
+		for _, e := range events {
+			emit(e)
+		}
+
+		if changed {
+			w.onChange()
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+//Poll reads the neighbors once and returns the events of their changes since the previous poll.
+//The MAC addresses are looked up without blocking Devices and SaveState, and a MAC address
+//being looked up by a Poll is not looked up again by the concurrent ones.
+func (w *Watcher) Poll(ctx context.Context) ([]Event, error) {
+	events, _, err := w.poll(ctx)
+
+	return events, err
+}
+
+// poll is Poll, also reporting whether the devices or vendors changed: with events, or with new lookup results.
+func (w *Watcher) poll(ctx context.Context) ([]Event, bool, error) {
+	neighbors, err := w.source.Neighbors(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	neighbors = groupByMAC(neighbors)
+	lookups := w.lookup(w.unresolved(neighbors))
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for mac := range lookups {
+		delete(w.pending, mac)
+	}
+
+	now := w.now()
+	seen := make(map[string]bool)
+	changed := false
+
+	var events []Event
+
+	for _, n := range neighbors {
+		seen[n.MAC] = true
+
+		d, known := w.devices[n.MAC]
+		if !known {
+			d = &Device{MAC: n.MAC, IP: n.IP, Interface: n.Interface, FirstSeen: now, Present: true}
+			w.devices[n.MAC] = d
+		}
+
+		d.LastSeen = now
+
+		if known && !d.Present {
+			d.Present = true
+			events = append(events, w.event(EventDeviceBack, d, now))
+		}
+
+		if isIPv4(n.IP) && isIPv4(d.IP) && n.IP != d.IP {
+			e := w.event(EventIPChange, d, now)
+			e.IP, e.PreviousIP = n.IP, d.IP
+			events = append(events, e)
+		}
+
+		if isIPv4(n.IP) || d.IP == "" {
+			d.IP = n.IP
+		}
+
+		d.Interface = n.Interface
+
+		newVendor := false
+		if l, ok := lookups[n.MAC]; ok && !d.Resolved {
+			lastErr := d.Error
+			newVendor = w.resolve(d, l)
+			changed = changed || d.Resolved || d.Error != lastErr
+		}
+
+		if !known {
+			events = append(events, w.event(EventNewDevice, d, now))
+		}
+
+		if newVendor {
+			events = append(events, w.event(EventNewVendor, d, now))
+		}
+	}
+
+	for _, d := range w.sortedDevices() {
+		if d.Present && !seen[d.MAC] && now.Sub(d.LastSeen) >= w.goneAfter {
+			d.Present = false
+			events = append(events, w.event(EventDeviceGone, d, now))
+		}
+	}
+
+	return events, changed || len(events) > 0, nil
+}
+
+//Devices returns every device seen, sorted by MAC address.
+func (w *Watcher) Devices() []Device {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var list []Device
+	for _, d := range w.sortedDevices() {
+		list = append(list, *d)
+	}
+
+	return list
+}
+
+//SaveState writes the devices and vendors seen in JSON, to be restored with LoadState.
+func (w *Watcher) SaveState(wr io.Writer) error {
+	s := state{Version: StateVersion, Devices: w.Devices()}
+
+	w.mu.Lock()
+	for v := range w.vendors {
+		s.Vendors = append(s.Vendors, v)
+	}
+	w.mu.Unlock()
+
+	sort.Strings(s.Vendors)
+
+	enc := json.NewEncoder(wr)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(s)
+}
+
+//LoadState restores the devices and vendors written by SaveState, replacing those seen.
+func (w *Watcher) LoadState(r io.Reader) error {
+	var s state
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return err
+	}
+
+	if s.Version < 0 || s.Version > StateVersion {
+		return fmt.Errorf("unsupported watch state version %d", s.Version)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.devices = make(map[string]*Device)
+	w.vendors = make(map[string]bool)
+
+	for _, d := range s.Devices {
+		d := d
+		w.devices[d.MAC] = &d
+	}
+
+	for _, v := range s.Vendors {
+		w.vendors[v] = true
+	}
+
+	return nil
+}
+
+type lookupResult struct {
+	info maclookup.ResponseMACInfo
+	err  error
+}
+
+// unresolved returns the MAC addresses of neighbors never resolved and not pending, and marks them pending.
+func (w *Watcher) unresolved(neighbors []Neighbor) []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var macs []string
+
+	for _, n := range neighbors {
+		if d, ok := w.devices[n.MAC]; (!ok || !d.Resolved) && !w.pending[n.MAC] {
+			w.pending[n.MAC] = true
+			macs = append(macs, n.MAC)
+		}
+	}
+
+	return macs
+}
+
+// lookup resolves macs. It must be called without w.mu held: a lookup can last the timeout of the resolver.
+func (w *Watcher) lookup(macs []string) map[string]lookupResult {
+	lookups := make(map[string]lookupResult, len(macs))
+
+	for _, mac := range macs {
+		info, err := w.resolver.Lookup(mac)
+		lookups[mac] = lookupResult{info: info, err: err}
+	}
+
+	return lookups
+}
+
+// resolve sets the lookup l of d and reports whether its vendor was never seen before.
+func (w *Watcher) resolve(d *Device, l lookupResult) bool {
+	if l.err != nil {
+		d.Error = l.err.Error()
+		return false
+	}
+
+	d.Info, d.Resolved, d.Error = l.info, true, ""
+
+	key := vendorKey(l.info)
+	if key == "" || w.vendors[key] {
+		return false
+	}
+
+	w.vendors[key] = true
+
+	return true
+}
+
+func (w *Watcher) event(t EventType, d *Device, now time.Time) Event {
+	e := Event{Type: t, Time: now, MAC: d.MAC, IP: d.IP, Interface: d.Interface, Error: d.Error}
+
+	if d.Resolved {
+		info := d.Info
+		e.Lookup = &info
+		e.Vendor = vendorName(info)
+	}
+
+	return e
+}
+
+func (w *Watcher) sortedDevices() []*Device {
+	list := make([]*Device, 0, len(w.devices))
+	for _, d := range w.devices {
+		list = append(list, d)
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].MAC < list[j].MAC })
+
+	return list
+}
+
+// groupByMAC returns one neighbor per MAC address, with its first IPv4 address or else its first address.
+func groupByMAC(neighbors []Neighbor) []Neighbor {
+	index := make(map[string]int)
+
+	var list []Neighbor
+
+	for _, n := range neighbors {
+		i, ok := index[n.MAC]
+		if !ok {
+			index[n.MAC] = len(list)
+			list = append(list, n)
+
+			continue
+		}
+
+		if !isIPv4(list[i].IP) && isIPv4(n.IP) {
+			list[i] = n
+		}
+	}
+
+	return list
+}
+
+func isIPv4(ip string) bool {
+	addr := net.ParseIP(ip)
+
+	return addr != nil && addr.To4() != nil
+}
+
+// vendorKey is the normalized vendor of a lookup, empty for unknown and private assignments.
+func vendorKey(info maclookup.ResponseMACInfo) string {
+	if info.Vendor.Key != "" {
+		return info.Vendor.Key
+	}
+
+	return maclookup.NormalizeCompany(info.Company).Key
+}
+
+func vendorName(info maclookup.ResponseMACInfo) string {
+	if info.Vendor.Name != "" {
+		return info.Vendor.Name
+	}
+
+	return info.Company
+}
+
+//WriteJSON writes e as a line of JSON (JSON Lines).
+func WriteJSON(w io.Writer, e Event) error {
+	return json.NewEncoder(w).Encode(e)
+}
+
+//WriteText writes e as a line of text: time, type, MAC address and the other non empty fields.
+func WriteText(w io.Writer, e Event) error {
+	_, err := fmt.Fprintln(w, e.String())
+
+	return err
+}
+
+//String returns the text of WriteText.
+func (e Event) String() string {
+	parts := []string{e.Time.Format(time.RFC3339), string(e.Type), e.MAC}
+
+	for _, f := range []struct{ name, value string }{
+		{"ip", e.IP},
+		{"previous_ip", e.PreviousIP},
+		{"dev", e.Interface},
+		{"vendor", e.Vendor},
+		{"error", e.Error},
+	} {
+		if f.value != "" {
+			parts = append(parts, fmt.Sprintf("%s=%q", f.name, f.value))
+		}
+	}
+
+	return strings.Join(parts, " ")
+}
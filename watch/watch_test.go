@@ -0,0 +1,374 @@
+package watch
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/logocomune/maclookup-go"
+	"github.com/logocomune/maclookup-go/maclookuptest"
+	"github.com/stretchr/testify/assert"
+)
+
+const procARP = `IP address       HW type     Flags       HW address            Mask     Device
+192.168.1.1      0x1         0x2         00:00:00:11:22:33     *        eth0
+192.168.1.20     0x1         0x0         00:00:00:00:00:00     *        eth0
+192.168.1.30     0x1         0x2         02:42:ac:11:00:02     *        docker0
+`
+
+const ipNeigh = `192.168.1.1 dev eth0 lladdr 00:00:00:11:22:33 REACHABLE
+fe80::1 dev eth0 lladdr 00:00:00:11:22:33 router STALE
+192.168.1.21 dev eth0  FAILED
+192.168.1.22 dev eth0 lladdr 00:00:00:aa:bb:cc INCOMPLETE
+192.168.1.40 dev wlan0 lladdr AC:DE:48:00:11:22 DELAY
+`
+
+func TestParseProcARP(t *testing.T) {
+	list, err := ParseProcARP(strings.NewReader(procARP))
+	assert.Nil(t, err)
+	assert.Equal(t, []Neighbor{
+		{IP: "192.168.1.1", MAC: "00:00:00:11:22:33", Interface: "eth0"},
+		{IP: "192.168.1.30", MAC: "02:42:ac:11:00:02", Interface: "docker0"},
+	}, list)
+}
+
+func TestParseIPNeigh(t *testing.T) {
+	list, err := ParseIPNeigh(strings.NewReader(ipNeigh))
+	assert.Nil(t, err)
+	assert.Equal(t, []Neighbor{
+		{IP: "192.168.1.1", MAC: "00:00:00:11:22:33", Interface: "eth0"},
+		{IP: "fe80::1", MAC: "00:00:00:11:22:33", Interface: "eth0"},
+		{IP: "192.168.1.40", MAC: "ac:de:48:00:11:22", Interface: "wlan0"},
+	}, list)
+}
+
+func TestMerge(t *testing.T) {
+	arp := SourceFunc(func(context.Context) ([]Neighbor, error) { return ParseProcARP(strings.NewReader(procARP)) })
+	neigh := SourceFunc(func(context.Context) ([]Neighbor, error) { return ParseIPNeigh(strings.NewReader(ipNeigh)) })
+	failing := SourceFunc(func(context.Context) ([]Neighbor, error) { return nil, errors.New("no ip command") })
+
+	list, err := Merge(arp, neigh, failing).Neighbors(context.Background())
+	assert.Nil(t, err)
+	assert.Len(t, list, 4)
+	assert.Equal(t, Neighbor{IP: "192.168.1.1", MAC: "00:00:00:11:22:33", Interface: "eth0"}, list[0])
+
+	_, err = Merge(failing).Neighbors(context.Background())
+	assert.NotNil(t, err)
+}
+
+func TestProcARP_missing(t *testing.T) {
+	_, err := ProcARP{Path: "testdata/missing"}.Neighbors(context.Background())
+	assert.NotNil(t, err)
+}
+
+// fakeSource returns the neighbors set by the test.
+type fakeSource struct {
+	neighbors []Neighbor
+	err       error
+}
+
+func (s *fakeSource) Neighbors(context.Context) ([]Neighbor, error) {
+	return s.neighbors, s.err
+}
+
+func eventTypes(events []Event) []EventType {
+	var types []EventType
+	for _, e := range events {
+		types = append(types, e.Type)
+	}
+
+	return types
+}
+
+func TestWatcher_Poll(t *testing.T) {
+	src := &fakeSource{}
+	fake := maclookuptest.NewFake()
+	fake.AddVendors(maclookup.MACInfo{Found: true, MacPrefix: "9C934E", Company: "Xerox Corporation"})
+
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	w := New(src, fake)
+	w.WithGoneAfter(time.Minute)
+	w.now = func() time.Time { return now }
+
+	ctx := context.Background()
+
+	src.neighbors = []Neighbor{
+		{IP: "fe80::1", MAC: "00:00:00:11:22:33", Interface: "eth0"},
+		{IP: "192.168.1.10", MAC: "00:00:00:11:22:33", Interface: "eth0"},
+		{IP: "192.168.1.11", MAC: "00:00:00:11:22:44", Interface: "eth0"},
+	}
+
+	events, err := w.Poll(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, []EventType{EventNewDevice, EventNewVendor, EventNewDevice}, eventTypes(events))
+	assert.Equal(t, "192.168.1.10", events[0].IP)
+	assert.Equal(t, "XEROX CORPORATION", events[0].Vendor)
+	assert.Equal(t, "US", events[0].Lookup.Country)
+	assert.Equal(t, now, events[0].Time)
+
+	// Same vendor under another spelling, IPv4 change, one device missing.
+	now = now.Add(30 * time.Second)
+	src.neighbors = []Neighbor{
+		{IP: "192.168.1.12", MAC: "00:00:00:11:22:33", Interface: "eth0"},
+		{IP: "192.168.1.13", MAC: "9c:93:4e:00:00:01", Interface: "eth0"},
+	}
+
+	events, err = w.Poll(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, []EventType{EventIPChange, EventNewDevice}, eventTypes(events))
+	assert.Equal(t, "192.168.1.12", events[0].IP)
+	assert.Equal(t, "192.168.1.10", events[0].PreviousIP)
+
+	now = now.Add(45 * time.Second)
+
+	events, err = w.Poll(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, []EventType{EventDeviceGone}, eventTypes(events))
+	assert.Equal(t, "00:00:00:11:22:44", events[0].MAC)
+
+	now = now.Add(time.Second)
+	src.neighbors = append(src.neighbors, Neighbor{IP: "192.168.1.11", MAC: "00:00:00:11:22:44", Interface: "eth0"})
+
+	events, err = w.Poll(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, []EventType{EventDeviceBack}, eventTypes(events))
+
+	devices := w.Devices()
+	assert.Len(t, devices, 3)
+	assert.True(t, devices[0].Present)
+	assert.Len(t, fake.Calls(), 3)
+
+	src.err = errors.New("read error")
+	_, err = w.Poll(ctx)
+	assert.NotNil(t, err)
+}
+
+func TestWatcher_retryLookup(t *testing.T) {
+	src := &fakeSource{neighbors: []Neighbor{{IP: "192.168.1.10", MAC: "00:00:00:11:22:33"}}}
+	fake := maclookuptest.NewFake()
+	fake.FailNext(1, &maclookup.RateLimitsExceeded{Err: maclookup.ErrRateLimited})
+
+	w := New(src, fake)
+
+	events, err := w.Poll(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, []EventType{EventNewDevice}, eventTypes(events))
+	assert.Nil(t, events[0].Lookup)
+	assert.NotEmpty(t, events[0].Error)
+
+	events, err = w.Poll(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, []EventType{EventNewVendor}, eventTypes(events))
+	assert.Equal(t, "XEROX CORPORATION", events[0].Vendor)
+	assert.Empty(t, events[0].Error)
+}
+
+// blockingResolver waits for release before resolving with the fake.
+type blockingResolver struct {
+	*maclookuptest.Fake
+	started chan struct{}
+	release chan struct{}
+}
+
+func (r blockingResolver) Lookup(mac string) (maclookup.ResponseMACInfo, error) {
+	r.started <- struct{}{}
+	<-r.release
+
+	return r.Fake.Lookup(mac)
+}
+
+func TestWatcher_lookupUnlocked(t *testing.T) {
+	src := &fakeSource{neighbors: []Neighbor{{IP: "192.168.1.10", MAC: "00:00:00:11:22:33"}}}
+	r := blockingResolver{Fake: maclookuptest.NewFake(), started: make(chan struct{}), release: make(chan struct{})}
+
+	w := New(src, r)
+
+	done := make(chan []Event)
+
+	go func() {
+		events, _ := w.Poll(context.Background())
+		done <- events
+	}()
+
+	<-r.started
+
+	// The pending lookup blocks neither Devices nor SaveState.
+	assert.Empty(t, w.Devices())
+	assert.Nil(t, w.SaveState(&bytes.Buffer{}))
+
+	close(r.release)
+
+	events := <-done
+	assert.Equal(t, []EventType{EventNewDevice, EventNewVendor}, eventTypes(events))
+	assert.Len(t, w.Devices(), 1)
+}
+
+func TestWatcher_concurrentPolls(t *testing.T) {
+	src := &fakeSource{neighbors: []Neighbor{{IP: "192.168.1.10", MAC: "00:00:00:11:22:33"}}}
+	r := blockingResolver{Fake: maclookuptest.NewFake(), started: make(chan struct{}), release: make(chan struct{})}
+
+	w := New(src, r)
+
+	done := make(chan []Event)
+
+	go func() {
+		events, _ := w.Poll(context.Background())
+		done <- events
+	}()
+
+	<-r.started
+
+	// The MAC address pending in the first poll is not looked up by the second one.
+	events, err := w.Poll(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, []EventType{EventNewDevice}, eventTypes(events))
+
+	close(r.release)
+
+	assert.Equal(t, []EventType{EventNewVendor}, eventTypes(<-done))
+	assert.Len(t, r.Calls(), 1)
+	assert.True(t, w.Devices()[0].Resolved)
+}
+
+func TestWatcher_state(t *testing.T) {
+	src := &fakeSource{neighbors: []Neighbor{{IP: "192.168.1.10", MAC: "00:00:00:11:22:33"}}}
+
+	w := New(src, maclookuptest.NewFake())
+	w.now = func() time.Time { return time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC) }
+
+	_, err := w.Poll(context.Background())
+	assert.Nil(t, err)
+
+	var buf bytes.Buffer
+	assert.Nil(t, w.SaveState(&buf))
+
+	restored := New(src, maclookuptest.NewFake())
+	assert.Nil(t, restored.LoadState(&buf))
+	assert.Equal(t, w.Devices(), restored.Devices())
+
+	src.neighbors = append(src.neighbors, Neighbor{IP: "192.168.1.11", MAC: "00:00:00:11:22:44"})
+
+	events, err := restored.Poll(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, []EventType{EventNewDevice}, eventTypes(events))
+
+	assert.NotNil(t, restored.LoadState(strings.NewReader(`{"version":2}`)))
+}
+
+func TestWatcher_Run(t *testing.T) {
+	src := &fakeSource{neighbors: []Neighbor{{IP: "192.168.1.10", MAC: "00:00:00:11:22:33"}}}
+
+	w := New(src, maclookuptest.NewFake())
+	w.WithInterval(time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var events []Event
+
+	err := w.Run(ctx, func(e Event) {
+		events = append(events, e)
+		if len(events) == 2 {
+			cancel()
+		}
+	})
+
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Equal(t, []EventType{EventNewDevice, EventNewVendor}, eventTypes(events))
+}
+
+// pollSource counts the polls and calls stop at the last one.
+type pollSource struct {
+	fakeSource
+	polls int
+	last  int
+	stop  func()
+}
+
+func (s *pollSource) Neighbors(ctx context.Context) ([]Neighbor, error) {
+	s.polls++
+	if s.polls == s.last {
+		s.stop()
+	}
+
+	return s.fakeSource.Neighbors(ctx)
+}
+
+func TestWatcher_OnChange(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	src := &pollSource{fakeSource: fakeSource{neighbors: []Neighbor{{IP: "192.168.1.10", MAC: "00:00:00:11:22:33"}}}, last: 3, stop: cancel}
+
+	w := New(src, maclookuptest.NewFake())
+	w.WithInterval(time.Millisecond)
+
+	changes := 0
+	w.OnChange(func() { changes++ })
+
+	err := w.Run(ctx, func(Event) {})
+	assert.True(t, errors.Is(err, context.Canceled))
+
+	// Only the first poll finds a new device.
+	assert.Equal(t, 3, src.polls)
+	assert.Equal(t, 1, changes)
+}
+
+func TestWriteText(t *testing.T) {
+	e := Event{
+		Type:      EventNewDevice,
+		Time:      time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC),
+		MAC:       "00:00:00:11:22:33",
+		IP:        "192.168.1.10",
+		Interface: "eth0",
+		Vendor:    "XEROX CORPORATION",
+	}
+
+	var buf bytes.Buffer
+	assert.Nil(t, WriteText(&buf, e))
+	assert.Equal(t, `2026-10-19T12:00:00Z new_device 00:00:00:11:22:33 ip="192.168.1.10" dev="eth0" vendor="XEROX CORPORATION"`+"\n", buf.String())
+
+	buf.Reset()
+	assert.Nil(t, WriteJSON(&buf, e))
+	assert.JSONEq(t, `{"type":"new_device","time":"2026-10-19T12:00:00Z","mac":"00:00:00:11:22:33","ip":"192.168.1.10","interface":"eth0","vendor":"XEROX CORPORATION"}`, buf.String())
+}
+
+func TestBroadcaster(t *testing.T) {
+	b := NewBroadcaster()
+
+	ts := httptest.NewServer(b)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	for b.Clients() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	b.Publish(Event{Type: EventDeviceGone, MAC: "00:00:00:11:22:33", Time: time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)})
+
+	r := bufio.NewReader(resp.Body)
+
+	var lines []string
+
+	for len(lines) < 3 {
+		line, err := r.ReadString('\n')
+		assert.Nil(t, err)
+		lines = append(lines, strings.TrimSuffix(line, "\n"))
+	}
+
+	assert.Equal(t, []string{
+		"id: 1",
+		"event: device_gone",
+		`data: {"type":"device_gone","time":"2026-10-19T12:00:00Z","mac":"00:00:00:11:22:33"}`,
+	}, lines)
+}
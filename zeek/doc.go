@@ -0,0 +1,3 @@
+// Package zeek adds vendor columns to Zeek logs (conn.log, dhcp.log and any log with MAC fields)
+// in both the TSV and the JSON formats.
+package zeek
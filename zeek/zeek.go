@@ -0,0 +1,268 @@
+package zeek
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/logocomune/maclookup-go"
+)
+
+const (
+	//VendorSuffix is appended to the name of a MAC field to name its vendor column.
+	VendorSuffix = "_vendor"
+
+	defaultSeparator = "\t"
+	defaultUnset     = "-"
+	maxLineSize      = 1 << 20
+)
+
+//IsMACField reports whether a Zeek field holds a MAC address:
+//"mac" (dhcp.log), "*_l2_addr" (conn.log with mac-logging) and "*_mac".
+func IsMACField(name string) bool {
+	return name == "mac" || strings.HasSuffix(name, "_l2_addr") || strings.HasSuffix(name, "_mac")
+}
+
+//Enrich copies a Zeek log from r to w adding a vendor column after the existing ones for every MAC field.
+//Both the TSV (ASCII) and the JSON log formats are supported and detected from the content.
+//Unknown vendors are written as unset values in TSV and omitted in JSON.
+func Enrich(r io.Reader, w io.Writer, res maclookup.CompanyNameResolver) error {
+	br := bufio.NewReaderSize(r, 64*1024)
+
+	first, err := firstByte(br)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		return err
+	}
+
+	e := &enricher{res: res, cache: make(map[string]string)}
+
+	if first == '{' {
+		return e.json(br, w)
+	}
+
+	return e.tsv(br, w)
+}
+
+type enricher struct {
+	res   maclookup.CompanyNameResolver
+	cache map[string]string
+}
+
+func (e *enricher) tsv(r io.Reader, w io.Writer) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), maxLineSize)
+
+	bw := bufio.NewWriter(w)
+	defer bw.Flush()
+
+	sep, unset := defaultSeparator, defaultUnset
+
+	var macCols []int
+
+	for sc.Scan() {
+		line := sc.Text()
+
+		if strings.HasPrefix(line, "#") {
+			// "#separator" is always followed by a space, never by the separator itself.
+			if f := strings.Fields(line); len(f) == 2 && f[0] == "#separator" {
+				sep = unescape(f[1])
+			}
+
+			directive := strings.SplitN(line, sep, 2)
+
+			switch directive[0] {
+			case "#unset_field":
+				if len(directive) == 2 {
+					unset = directive[1]
+				}
+			case "#fields":
+				fields := strings.Split(line, sep)[1:]
+				macCols = macCols[:0]
+
+				for i, name := range fields {
+					if IsMACField(name) {
+						macCols = append(macCols, i)
+						line += sep + name + VendorSuffix
+					}
+				}
+			case "#types":
+				for range macCols {
+					line += sep + "string"
+				}
+			}
+
+			if _, err := fmt.Fprintln(bw, line); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		values := strings.Split(line, sep)
+
+		for _, col := range macCols {
+			vendor := unset
+
+			if col < len(values) && values[col] != unset {
+				v, err := e.vendor(values[col])
+				if err != nil {
+					return err
+				}
+
+				if v != "" {
+					vendor = v
+				}
+			}
+
+			line += sep + vendor
+		}
+
+		if _, err := fmt.Fprintln(bw, line); err != nil {
+			return err
+		}
+	}
+
+	return sc.Err()
+}
+
+func (e *enricher) json(r io.Reader, w io.Writer) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), maxLineSize)
+
+	bw := bufio.NewWriter(w)
+	defer bw.Flush()
+
+	lineNo := 0
+
+	for sc.Scan() {
+		lineNo++
+
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var record map[string]interface{}
+		if err := json.Unmarshal(line, &record); err != nil {
+			return fmt.Errorf("zeek: line %d: %w", lineNo, err)
+		}
+
+		names := make([]string, 0, len(record))
+		for name := range record {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+
+		// Vendor keys are appended to the original text to keep the field order of the log.
+		out := append([]byte{}, line[:len(line)-1]...)
+
+		for _, name := range names {
+			mac, ok := record[name].(string)
+			if !ok || !IsMACField(name) {
+				continue
+			}
+
+			vendor, err := e.vendor(mac)
+			if err != nil {
+				return err
+			}
+
+			if vendor == "" {
+				continue
+			}
+
+			key, _ := json.Marshal(name + VendorSuffix)
+			value, _ := json.Marshal(vendor)
+
+			if len(record) > 0 {
+				out = append(out, ',')
+			}
+
+			out = append(append(append(out, key...), ':'), value...)
+		}
+
+		out = append(out, '}', '\n')
+
+		if _, err := bw.Write(out); err != nil {
+			return err
+		}
+	}
+
+	return sc.Err()
+}
+
+// vendor returns the company of mac, or an empty string when it is unknown.
+func (e *enricher) vendor(mac string) (string, error) {
+	if _, err := net.ParseMAC(mac); err != nil {
+		return "", nil
+	}
+
+	key := maclookup.Prefix(mac)
+
+	if v, ok := e.cache[key]; ok {
+		return v, nil
+	}
+
+	resp, err := e.res.CompanyName(mac)
+	if err != nil {
+		return "", err
+	}
+
+	var vendor string
+
+	switch {
+	case resp.IsPrivate:
+		vendor = maclookup.VendorPrivate
+	case resp.Found:
+		vendor = resp.Company
+	}
+
+	e.cache[key] = vendor
+
+	return vendor, nil
+}
+
+func firstByte(br *bufio.Reader) (byte, error) {
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+
+		if b != ' ' && b != '\t' && b != '\r' && b != '\n' {
+			return b, br.UnreadByte()
+		}
+	}
+}
+
+// unescape decodes the \xHH sequences Zeek uses in the #separator header.
+func unescape(s string) string {
+	var b strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) && s[i+1] == 'x' {
+			if v, err := strconv.ParseUint(s[i+2:i+4], 16, 8); err == nil {
+				b.WriteByte(byte(v))
+				i += 3
+
+				continue
+			}
+		}
+
+		b.WriteByte(s[i])
+	}
+
+	return b.String()
+}
@@ -0,0 +1,98 @@
+package zeek
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/logocomune/maclookup-go"
+	"github.com/stretchr/testify/assert"
+)
+
+const dhcpLogTSV = "#separator \\x09\n" +
+	"#set_separator\t,\n" +
+	"#empty_field\t(empty)\n" +
+	"#unset_field\t-\n" +
+	"#path\tdhcp\n" +
+	"#fields\tts\tuids\tclient_addr\tmac\thost_name\n" +
+	"#types\ttime\tset[string]\taddr\tstring\tstring\n" +
+	"1637229600.000000\tCaBc1\t192.168.1.10\t00:00:00:11:22:33\tlaptop\n" +
+	"1637229601.000000\tCaBc2\t192.168.1.11\t02:42:ac:11:00:02\t-\n" +
+	"1637229602.000000\tCaBc3\t192.168.1.12\t-\t-\n" +
+	"#close\t2021-11-18-10-00-00\n"
+
+const connLogJSON = `{"ts":1637229600.0,"uid":"CaBc1","id.orig_h":"192.168.1.10","orig_l2_addr":"00:00:00:11:22:33","resp_l2_addr":"ac:de:48:00:11:22"}
+{"ts":1637229601.0,"uid":"CaBc2","id.orig_h":"192.168.1.11"}
+`
+
+type fakeResolver struct {
+	calls int
+	err   error
+}
+
+func (f *fakeResolver) CompanyName(mac string) (maclookup.ResponseVendorName, error) {
+	f.calls++
+
+	var r maclookup.ResponseVendorName
+
+	switch {
+	case strings.HasPrefix(mac, "00:00:00"):
+		r.Found, r.Company = true, "XEROX CORPORATION"
+	case strings.HasPrefix(mac, "ac:de:48"):
+		r.Found, r.IsPrivate = true, true
+	}
+
+	return r, f.err
+}
+
+func TestEnrich_TSV(t *testing.T) {
+	var out bytes.Buffer
+
+	r := &fakeResolver{}
+	err := Enrich(strings.NewReader(dhcpLogTSV), &out, r)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, r.calls)
+
+	lines := strings.Split(out.String(), "\n")
+	assert.Equal(t, "#fields\tts\tuids\tclient_addr\tmac\thost_name\tmac_vendor", lines[5])
+	assert.Equal(t, "#types\ttime\tset[string]\taddr\tstring\tstring\tstring", lines[6])
+	assert.Equal(t, "1637229600.000000\tCaBc1\t192.168.1.10\t00:00:00:11:22:33\tlaptop\tXEROX CORPORATION", lines[7])
+	assert.Equal(t, "1637229601.000000\tCaBc2\t192.168.1.11\t02:42:ac:11:00:02\t-\t-", lines[8])
+	assert.Equal(t, "1637229602.000000\tCaBc3\t192.168.1.12\t-\t-\t-", lines[9])
+	assert.Equal(t, "#close\t2021-11-18-10-00-00", lines[10])
+}
+
+func TestEnrich_JSON(t *testing.T) {
+	var out bytes.Buffer
+
+	err := Enrich(strings.NewReader(connLogJSON), &out, &fakeResolver{})
+
+	assert.Nil(t, err)
+
+	lines := strings.Split(out.String(), "\n")
+	assert.Equal(t, `{"ts":1637229600.0,"uid":"CaBc1","id.orig_h":"192.168.1.10","orig_l2_addr":"00:00:00:11:22:33","resp_l2_addr":"ac:de:48:00:11:22","orig_l2_addr_vendor":"XEROX CORPORATION","resp_l2_addr_vendor":"(private)"}`, lines[0])
+	assert.Equal(t, `{"ts":1637229601.0,"uid":"CaBc2","id.orig_h":"192.168.1.11"}`, lines[1])
+}
+
+func TestEnrich_Error(t *testing.T) {
+	var out bytes.Buffer
+
+	err := Enrich(strings.NewReader(connLogJSON), &out, &fakeResolver{err: &maclookup.RateLimitsExceeded{}})
+
+	var e *maclookup.RateLimitsExceeded
+
+	assert.True(t, errors.As(err, &e))
+
+	err = Enrich(strings.NewReader("{not json}\n"), &out, &fakeResolver{})
+	assert.NotNil(t, err)
+}
+
+func TestIsMACField(t *testing.T) {
+	assert.True(t, IsMACField("mac"))
+	assert.True(t, IsMACField("orig_l2_addr"))
+	assert.True(t, IsMACField("src_mac"))
+	assert.False(t, IsMACField("id.orig_h"))
+	assert.False(t, IsMACField("macro"))
+}